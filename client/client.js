@@ -6,11 +6,11 @@
 // CONNECTION & STATE
 // ============================================================================
 
-// Auto-detect local vs production server
-const isLocal = window.location.hostname === "localhost"
-    || window.location.hostname === "127.0.0.1"
-    || window.location.protocol === "file:";
-const wsUrl = isLocal ? "ws://localhost:8080/ws" : "ws://134.199.204.89/ws";
+// The server that serves the page also serves the WebSocket
+// (opened straight from disk, assume a local server)
+const wsUrl = window.location.protocol === "file:"
+    ? "ws://localhost:8080/ws"
+    : `${window.location.protocol === "https:" ? "wss:" : "ws:"}//${window.location.host}/ws`;
 let ws = new WebSocket(wsUrl);
 
 // Player state
@@ -35,6 +35,16 @@ let cardDB = {};           // Local card database
 let inDrawPhase = false;
 let myLeader = 0;
 let opponentLeader = 0;
+let myLeaderTax = 0;            // Extra colorless cost after the leader died
+let opponentLeaderTax = 0;
+let myLeaderPowerUsed = false;  // Hero power is once per turn
+
+// Free-for-all state: the opponent area shows one opponent at a time,
+// every other opponent's board is kept here until it's picked
+let turnOrder = [];
+let opponentBoards = {};        // uid -> { health, field, lands, leader, leaderTax, eliminated }
+let teams = null;               // Team games: uid -> team (0 or 1)
+let sharedLife = false;         // Team games: teammates share one life total
 
 // Combat state
 let combatMode = false;
@@ -108,12 +118,14 @@ ws.onopen = () => {
     // Request card and deck lists
     ws.send(JSON.stringify({ type: "get_cards" }));
     ws.send(JSON.stringify({ type: "get_decks" }));
+    ws.send(JSON.stringify({ type: "get_rule_sets" }));
 
     // Check for saved game state and auto-reconnect
     const savedGameId = getCookie('tcg_gameId');
     const savedPlayerUid = getCookie('tcg_playerUid');
     if (savedPlayerUid) {
         document.getElementById("uid").value = savedPlayerUid;
+        listMyDecks();
     }
     if (savedGameId && savedPlayerUid) {
         setStatus(`Attempting to reconnect to ${savedGameId}...`);
@@ -153,36 +165,82 @@ ws.onmessage = (msg) => {
 
     // Handle specific events
     for (const event of events) {
+        const shown = showBoardFor(event);
         switch (event.type) {
             case "GameCreated":
                 gameId = event.data.gameId;
                 saveGameState();
-                setStatus("Waiting for opponent to join... (Game ID: " + gameId + ")");
+                matchState = event.data.matchId ? { matchId: event.data.matchId, bestOf: event.data.bestOf, wins: {} } : null;
+                if (event.data.inviteCode) {
+                    setStatus(`Private game - share invite code ${event.data.inviteCode} (Game ID: ${gameId})`);
+                } else {
+                    setStatus("Waiting for opponent to join... (Game ID: " + gameId + ")" + (event.data.locked ? " [password]" : ""));
+                }
+                break;
+
+            case "LobbyJoined":
+            case "PlayerKicked":
+                showLobbyPlayers(event.data);
+                break;
+
+            case "Kicked":
+                gameId = "";
+                leaveGame();
+                setStatus("The host removed you from the game");
                 break;
 
             case "CardList":
                 cardDB = event.data.cards;
                 log("Loaded " + Object.keys(cardDB).length + " cards");
+                if (gameId) {
+                    renderHand();
+                    renderField();
+                    renderLands();
+                }
+                break;
+
+            case "CardListUpdated":
+                // Card data was reloaded on the server - refresh our cache
+                // (in a game, the server keeps sending that game's cards)
+                log("Card data updated (version " + event.data.version + ")");
+                ws.send(JSON.stringify({ type: "get_cards" }));
+                ws.send(JSON.stringify({ type: "get_decks" }));
+                break;
+
+            case "ServerDraining":
+                if (event.data.draining) {
+                    log("The server is restarting soon - running games play on, but no new games can start");
+                    setStatus("Server restarting soon - no new games for now");
+                } else {
+                    log("The server is starting new games again");
+                    setStatus("The server is starting new games again");
+                }
+                break;
+
+            case "ServerShuttingDown":
+                serverRestartDelay = (event.data.reconnectAfterSeconds || 5) * 1000;
+                log(`The server is shutting down - ${event.data.runningGames} game(s) still running`);
+                setStatus("Server shutting down - you'll be reconnected once it's back");
                 break;
 
             case "MulliganPhase":
+                document.getElementById("lobby-host-controls").style.display = "none";
                 gameId = event.data.gameId;
+                // Make sure our card cache matches the card data this game uses
+                ws.send(JSON.stringify({ type: "get_cards" }));
                 // Get our hand and leader from the players info
                 if (event.data.players[myUID]) {
                     myHand = event.data.players[myUID].hand || [];
                     myLeader = event.data.players[myUID].leader || 0;
+                    myHealth = event.data.players[myUID].life || myHealth;
                     myDeckSize = event.data.players[myUID].deckSize || 0;
                     myVaultSize = event.data.players[myUID].vaultSize || 0;
                     myDiscardSize = event.data.players[myUID].discardSize || 0;
                 }
-                // Find opponent UID and their leader
-                for (const uid of Object.keys(event.data.players)) {
-                    if (uid !== myUID) {
-                        window.opponentUID = uid;
-                        opponentLeader = event.data.players[uid].leader || 0;
-                        break;
-                    }
-                }
+                // Find our opponents and their leaders
+                teams = event.data.teams || null;
+                sharedLife = event.data.sharedLife || false;
+                setupOpponents(event.data.players, event.data.turnOrder);
                 saveGameState();
                 log("Mulligan phase - choose to keep or mulligan your hand");
                 showMulliganUI();
@@ -216,18 +274,15 @@ ws.onmessage = (msg) => {
                 if (event.data.players[myUID]) {
                     myHand = event.data.players[myUID].hand || [];
                     myLeader = event.data.players[myUID].leader || 0;
+                    myHealth = event.data.players[myUID].life || myHealth;
                     myDeckSize = event.data.players[myUID].deckSize || 0;
                     myVaultSize = event.data.players[myUID].vaultSize || 0;
                     myDiscardSize = event.data.players[myUID].discardSize || 0;
                 }
-                // Find opponent UID and their leader
-                for (const uid of Object.keys(event.data.players)) {
-                    if (uid !== myUID) {
-                        window.opponentUID = uid;
-                        opponentLeader = event.data.players[uid].leader || 0;
-                        break;
-                    }
-                }
+                // Find our opponents and their leaders
+                teams = event.data.teams || null;
+                sharedLife = event.data.sharedLife || false;
+                setupOpponents(event.data.players, event.data.turnOrder);
                 saveGameState();
                 hideMulliganUI();
                 document.getElementById("game-controls").style.display = "block";
@@ -235,6 +290,7 @@ ws.onmessage = (msg) => {
                 log("Game started!");
                 renderHand();
                 renderLeaders();
+                updateHealthDisplay();
                 updateTurnStatus();
                 break;
 
@@ -252,9 +308,16 @@ ws.onmessage = (msg) => {
                     myLands.forEach(fc => {
                         if (fc.status) fc.status.Tapped = 0;
                     });
+                    // Frozen cards stay tapped
+                    for (const id of (event.data.frozen || [])) {
+                        const fc = findFieldCard(myField, id) || findFieldCard(myLands, id);
+                        if (fc && fc.status) fc.status.Tapped = 1;
+                    }
                     myManaPool = { White: 0, Blue: 0, Black: 0, Red: 0, Green: 0, Colorless: 0 };
+                    myLeaderPowerUsed = false;
                     renderField();
                     renderLands();
+                    renderLeaders();
                     updateManaPoolDisplay();
                 }
                 updateTurnStatus();
@@ -339,6 +402,34 @@ ws.onmessage = (msg) => {
                 }
                 break;
 
+            case "LeaderReturned":
+                // Leader went back to the leader zone (died or bounced)
+                if (event.data.player === myUID) {
+                    myLeader = event.data.cardId;
+                    myLeaderTax = event.data.recastTax || 0;
+                    myField = myField.filter(fc => fc.instanceId !== event.data.instanceId);
+                    if (event.data.died) myDiscardSize = Math.max(0, myDiscardSize - 1);
+                    renderField();
+                } else {
+                    opponentLeader = event.data.cardId;
+                    opponentLeaderTax = event.data.recastTax || 0;
+                    opponentField = opponentField.filter(fc => fc.instanceId !== event.data.instanceId);
+                    renderOpponentField();
+                }
+                renderLeaders();
+                log(`${event.data.player === myUID ? "Your" : "Opponent's"} leader returned to the leader zone (recast tax ${event.data.recastTax || 0})`);
+                break;
+
+            case "LeaderPowerUsed":
+                if (event.data.player === myUID) {
+                    myLeaderPowerUsed = true;
+                    myManaPool = event.data.manaPool;
+                    updateManaPoolDisplay();
+                    renderLeaders();
+                }
+                log(`${event.data.player === myUID ? "You" : "Opponent"} used hero power ${event.data.powerName || ""}`);
+                break;
+
             case "LandPlayed":
                 // Land moved from hand to lands area
                 if (event.data.player === myUID) {
@@ -492,23 +583,157 @@ ws.onmessage = (msg) => {
 
             case "GameOver":
                 const winner = event.data.winner;
-                if (winner === myUID) {
-                    setTurnStatus("You win!");
+                const change = (event.data.ratingChanges || {})[myUID];
+                const ratingText = change === undefined ? "" : ` (rating ${change >= 0 ? "+" : ""}${change})`;
+                if (winner === myUID || (event.data.winners || []).includes(myUID)) {
+                    setTurnStatus("You win!" + ratingText);
                 } else {
-                    setTurnStatus("You lose!");
+                    setTurnStatus("You lose!" + ratingText);
                 }
                 clearGameState();
                 disableGameControls();
                 break;
 
+            case "SideboardPhase":
+                matchState = event.data;
+                setTurnStatus(`${matchScoreText(event.data)} - sideboarding for game ${event.data.gameNumber + 1}`);
+                ws.send(JSON.stringify({ type: "get_match_deck" }));
+                break;
+
+            case "MatchDeck":
+                promptSideboard(event.data.deck);
+                break;
+
+            case "MatchUpdate":
+                matchState = event.data;
+                showMatchWaiting(event.data);
+                break;
+
+            case "MatchGameStarting":
+                matchState = event.data;
+                resetBoardForNextGame();
+                setStatus(`${matchScoreText(event.data)} - game ${event.data.gameNumber} starting`);
+                break;
+
+            case "MatchOver":
+                matchState = null;
+                setTurnStatus(`${event.data.winner === myUID ? "You win the match" : "You lose the match"} ${matchScoreText(event.data)}` +
+                    (event.data.reason === "forfeit" ? " (forfeit)" : ""));
+                break;
+
             case "OpponentLeft":
+                if (event.data.continues) {
+                    log(`${event.data.player} left the game`);
+                    break;
+                }
                 setTurnStatus("Opponent left - You win!");
                 clearGameState();
                 disableGameControls();
                 break;
 
+            case "PlayerEliminated":
+                if (event.data.player === myUID) {
+                    setTurnStatus("You were knocked out - " + event.data.reason);
+                    clearGameState();
+                    disableGameControls();
+                } else {
+                    const board = opponentBoards[event.data.player];
+                    if (board) board.eliminated = true;
+                    if (event.data.player === window.opponentUID) {
+                        opponentField = [];
+                        opponentLands = [];
+                        renderOpponentField();
+                        renderOpponentLands();
+                    }
+                    log(`${event.data.player} was knocked out (${event.data.reason}) - ${event.data.remaining.length} players left`);
+                }
+                break;
+
             case "DeckList":
-                populateDeckSelect(event.data.decks);
+                builtInDecks = event.data.decks;
+                populateDeckSelect();
+                break;
+
+            case "RuleSetList":
+                populateRuleSetSelect(event.data.ruleSets || []);
+                break;
+
+            case "TurnTimedOut":
+                log(`${event.data.player === myUID ? "Your" : event.data.player + "'s"} turn ran out of time (${event.data.turnSeconds}s)`);
+                break;
+
+            case "HandLimitDiscard":
+                if (event.data.player === myUID) {
+                    for (const cardId of event.data.discarded) {
+                        const idx = myHand.lastIndexOf(cardId);
+                        if (idx !== -1) myHand.splice(idx, 1);
+                        myDiscardSize++;
+                    }
+                    renderHand();
+                    updateDeckDisplay();
+                }
+                log(`${event.data.player === myUID ? "You" : event.data.player} discarded ${event.data.discarded.length} card(s) down to the hand limit`);
+                break;
+
+            case "MyDeckList":
+                myDecks = event.data.decks || [];
+                populateDeckSelect();
+                break;
+
+            case "DeckSaved":
+                setStatus(`Deck saved: ${event.data.deck.name}`);
+                listMyDecks();
+                if (event.data.draftId) {
+                    document.getElementById("draft-status").textContent = `Built ${event.data.deck.name} - pick it from your decks to play`;
+                }
+                break;
+
+            case "DraftUpdate":
+                myDraft = event.data.draft;
+                renderDraft();
+                break;
+
+            case "DraftClosed":
+                myDraft = null;
+                renderDraft();
+                document.getElementById("draft-status").textContent = `Draft ${event.data.draftId} was closed by its host`;
+                break;
+
+            case "QueueJoined":
+            case "QueueStatus":
+                showQueueStatus(event.data);
+                break;
+
+            case "QueueLeft":
+                hideQueueStatus();
+                setStatus(event.data.reason === "cancelled" ? "Left the queue" : `Left the queue: ${event.data.reason}`);
+                break;
+
+            case "MatchFound":
+                hideQueueStatus();
+                setStatus(`Match found${event.data.ranked ? " (ranked)" : ""}: ${event.data.players.join(" vs ")}`);
+                showInGameLobby();
+                break;
+
+            case "Profile":
+                showProfile(event.data.profile, event.data.season, event.data.rating);
+                break;
+
+            case "Leaderboard":
+                showLeaderboard(event.data.season, event.data.players || []);
+                break;
+
+            case "MatchHistory":
+                showMatchHistory(event.data.player, event.data.matches || [], event.data.results || []);
+                break;
+
+            case "DeckCode":
+                prompt(`Deck code for ${event.data.name} (copy to share):`, event.data.code);
+                break;
+
+            case "DeckDeleted":
+                setStatus("Deck deleted");
+                listMyDecks();
                 break;
 
             case "GameList":
@@ -530,10 +755,16 @@ ws.onmessage = (msg) => {
                 myVaultSize = event.data.myVaultSize || 0;
                 myDiscardSize = event.data.myDiscardSize || 0;
                 myLeader = event.data.myLeader || 0;
+                myLeaderTax = event.data.myLeaderTax || 0;
+                myLeaderPowerUsed = event.data.myLeaderPowerUsed || false;
+                opponentLeaderTax = event.data.opponentLeaderTax || 0;
                 opponentHealth = event.data.opponentLife;
                 opponentField = event.data.opponentField || [];
                 opponentLands = event.data.opponentLands || [];
                 opponentLeader = event.data.opponentLeader || 0;
+                teams = event.data.teams || null;
+                sharedLife = event.data.sharedLife || false;
+                setupOpponents(event.data.players || {}, event.data.turnOrder, window.opponentUID);
                 inDrawPhase = event.data.drawPhase || false;
 
                 // Update UI
@@ -598,7 +829,7 @@ ws.onmessage = (msg) => {
                 break;
 
             case "ChatMessage":
-                addChatMessage(event.data.player, event.data.message);
+                addChatMessage(event.data.player, (event.data.channel === "team" ? "[team] " : "") + event.data.message);
                 break;
 
             // DISABLED - blocking removed
@@ -704,22 +935,97 @@ ws.onmessage = (msg) => {
             case "ScriptBuff":
                 {
                     const targetId = event.data.targetInstanceId;
+                    const temporary = event.data.duration && event.data.duration !== "permanent";
+                    const applyBuff = (fc) => {
+                        if (temporary) {
+                            fc.effectAttack = (fc.effectAttack || 0) + event.data.attackMod;
+                            fc.effectHealth = (fc.effectHealth || 0) + event.data.healthMod;
+                        } else {
+                            fc.damageModifier = (fc.damageModifier || 0) + event.data.attackMod;
+                            fc.healthModifier = (fc.healthModifier || 0) + event.data.healthMod;
+                        }
+                        fc.currentHealth = event.data.newHealth;
+                    };
                     let found = myField.find(fc => fc.instanceId === targetId);
                     if (found) {
-                        found.damageModifier = (found.damageModifier || 0) + event.data.attackMod;
-                        found.healthModifier = (found.healthModifier || 0) + event.data.healthMod;
-                        found.currentHealth = event.data.newHealth;
+                        applyBuff(found);
                         renderField();
                     } else {
                         found = opponentField.find(fc => fc.instanceId === targetId);
                         if (found) {
-                            found.damageModifier = (found.damageModifier || 0) + event.data.attackMod;
-                            found.healthModifier = (found.healthModifier || 0) + event.data.healthMod;
-                            found.currentHealth = event.data.newHealth;
+                            applyBuff(found);
+                            renderOpponentField();
+                        }
+                    }
+                    log(`Creature ${targetId} buffed +${event.data.attackMod}/+${event.data.healthMod}` + (temporary ? ` (${event.data.duration})` : ""));
+                }
+                break;
+
+            case "AbilityActivated":
+                if (event.data.player === myUID) {
+                    myManaPool = event.data.manaPool;
+                    updateManaPoolDisplay();
+                }
+                log(`${event.data.player === myUID ? "You" : "Opponent"} activated ${event.data.abilityName || "an ability"}`);
+                break;
+
+            case "StatusApplied":
+                updateAnyFieldCard(event.data.instanceId, fc => {
+                    fc.status = fc.status || {};
+                    fc.statusTurns = fc.statusTurns || {};
+                    fc.status[event.data.status] = event.data.stacks;
+                    if (event.data.turns > 0) {
+                        fc.statusTurns[event.data.status] = event.data.turns;
+                    } else {
+                        delete fc.statusTurns[event.data.status];
+                    }
+                });
+                log(`Creature ${event.data.instanceId} is ${event.data.status} (${event.data.stacks})`);
+                break;
+
+            case "StatusExpired":
+                updateAnyFieldCard(event.data.instanceId, fc => {
+                    if (fc.status) delete fc.status[event.data.status];
+                    if (fc.statusTurns) delete fc.statusTurns[event.data.status];
+                });
+                log(`Creature ${event.data.instanceId} is no longer ${event.data.status}`);
+                break;
+
+            case "ShieldAbsorbed":
+                updateAnyFieldCard(event.data.instanceId, fc => {
+                    fc.status = fc.status || {};
+                    fc.status.Shielded = event.data.stacksRemaining;
+                });
+                log(`Shield absorbed ${event.data.amount} damage on creature ${event.data.instanceId}`);
+                break;
+
+            case "StatusDamage":
+                updateAnyFieldCard(event.data.instanceId, fc => {
+                    fc.currentHealth = event.data.newHealth;
+                });
+                log(`Creature ${event.data.instanceId} took ${event.data.amount} ${event.data.status} damage`);
+                break;
+
+            case "EffectExpired":
+                {
+                    const targetId = event.data.targetInstanceId;
+                    const removeBuff = (fc) => {
+                        fc.effectAttack = (fc.effectAttack || 0) - event.data.attackMod;
+                        fc.effectHealth = (fc.effectHealth || 0) - event.data.healthMod;
+                        if (event.data.newHealth !== undefined) fc.currentHealth = event.data.newHealth;
+                    };
+                    let found = myField.find(fc => fc.instanceId === targetId);
+                    if (found) {
+                        removeBuff(found);
+                        renderField();
+                    } else {
+                        found = opponentField.find(fc => fc.instanceId === targetId);
+                        if (found) {
+                            removeBuff(found);
                             renderOpponentField();
                         }
                     }
-                    log(`Creature ${targetId} buffed +${event.data.attackMod}/+${event.data.healthMod}`);
+                    log(`Effect on creature ${targetId} expired (-${event.data.attackMod}/-${event.data.healthMod})`);
                 }
                 break;
 
@@ -731,6 +1037,24 @@ ws.onmessage = (msg) => {
                 }
                 break;
 
+            case "AltCostPaid":
+                // Alternative cost: life paid and/or cards discarded
+                if (event.data.player === myUID) {
+                    myHealth = event.data.newLife;
+                    for (const cardId of (event.data.discarded || [])) {
+                        const idx = myHand.indexOf(cardId);
+                        if (idx !== -1) myHand.splice(idx, 1);
+                        myDiscardSize++;
+                    }
+                    renderHand();
+                    updateDeckDisplay();
+                } else {
+                    opponentHealth = event.data.newLife;
+                }
+                updateHealthDisplay();
+                log(`${event.data.player === myUID ? "You" : "Opponent"} paid alternative cost: ${event.data.name}`);
+                break;
+
             case "ScriptDiscard":
                 if (event.data.player === myUID) {
                     // Remove discarded cards from hand
@@ -861,13 +1185,30 @@ ws.onmessage = (msg) => {
                 }
                 break;
         }
+        restoreBoard(shown);
+        shareLife(event);
     }
+    renderOpponentList();
 };
 
 ws.onclose = () => {
     log("Disconnected from server");
+    if (serverRestartDelay) {
+        setTimeout(reloadWhenServerIsBack, serverRestartDelay);
+    }
 };
 
+// After a server shutdown, wait until the server answers again and reload the page,
+// which reconnects to the saved game
+function reloadWhenServerIsBack() {
+    fetch(window.location.href, { cache: "no-store" })
+        .then(resp => {
+            if (!resp.ok) throw new Error(resp.statusText);
+            window.location.reload();
+        })
+        .catch(() => setTimeout(reloadWhenServerIsBack, serverRestartDelay));
+}
+
 function getUID() {
     return document.getElementById("uid").value.trim();
 }
@@ -877,15 +1218,246 @@ function getSelectedDeck() {
     return parseInt(select.value) || 0;
 }
 
-function populateDeckSelect(decks) {
+// Built-in decks from get_decks and the player's own decks from list_my_decks
+let builtInDecks = [];
+let myDecks = [];
+let matchState = null; // Score of the best-of-N match we're playing, if any
+let myDraft = null;    // The draft or sealed pool we're in, if any
+let serverRestartDelay = 0; // Set when the server warns it's shutting down: wait this long before reconnecting
+
+function populateDeckSelect() {
     const select = document.getElementById("deck-select");
+    const selected = select.value;
     select.innerHTML = '<option value="">-- Select a deck --</option>';
-    for (const deck of decks) {
+    for (const deck of builtInDecks.concat(myDecks)) {
         const option = document.createElement("option");
         option.value = deck.id;
-        option.textContent = `${deck.name} (Leader: ${deck.leaderName})`;
+        option.textContent = `${deck.name} (Leader: ${deck.leaderName})` + (deck.custom ? " [custom]" : "");
         select.appendChild(option);
     }
+    select.value = selected;
+}
+
+function listMyDecks() {
+    const uid = getUID();
+    if (!uid) return;
+    ws.send(JSON.stringify({ playerUid: uid, type: "list_my_decks" }));
+}
+
+// Parse "101, 101x3, 105" into a list of card IDs (NxM adds M copies)
+function parseCardList(text) {
+    const ids = [];
+    for (const part of text.split(",")) {
+        const match = part.trim().match(/^(\d+)(?:\s*x\s*(\d+))?$/i);
+        if (!match) continue;
+        const count = match[2] ? parseInt(match[2]) : 1;
+        for (let i = 0; i < count; i++) ids.push(parseInt(match[1]));
+    }
+    return ids;
+}
+
+function formatCardList(ids) {
+    const counts = {};
+    for (const id of ids) counts[id] = (counts[id] || 0) + 1;
+    return Object.entries(counts).map(([id, n]) => n > 1 ? `${id}x${n}` : id).join(", ");
+}
+
+// Prompt for a deck's contents, starting from an existing deck when editing
+function promptDeck(base) {
+    const name = prompt("Deck name:", base ? base.name : "");
+    if (!name) return null;
+    const leader = parseInt(prompt("Leader card ID (a creature):", base ? base.leaderId : ""));
+    if (!leader) return null;
+    const mainDeck = prompt("Main deck card IDs, e.g. 105x3, 106, 107 (no lands):", base ? formatCardList(base.mainDeck) : "");
+    if (mainDeck === null) return null;
+    const vault = prompt("Vault land IDs, e.g. 101x8, 102x7:", base ? formatCardList(base.vault) : "");
+    if (vault === null) return null;
+    const sideboard = prompt("Sideboard card IDs for best-of matches (optional):", base ? formatCardList(base.sideboard || []) : "");
+    if (sideboard === null) return null;
+    return {
+        ID: base ? base.id : 0,
+        Name: name,
+        Leader: leader,
+        MainDeck: parseCardList(mainDeck),
+        Vault: parseCardList(vault),
+        Sideboard: parseCardList(sideboard)
+    };
+}
+
+function selectedCustomDeck() {
+    const id = getSelectedDeck();
+    return myDecks.find(d => d.id === id);
+}
+
+function newDeck() {
+    const uid = getUID();
+    if (!uid) {
+        alert("Please enter a UID");
+        return;
+    }
+    const deck = promptDeck(null);
+    if (!deck) return;
+    ws.send(JSON.stringify({ playerUid: uid, type: "save_deck", deck: deck }));
+}
+
+function editDeck() {
+    const base = selectedCustomDeck();
+    if (!base) {
+        alert("Select one of your custom decks to edit");
+        return;
+    }
+    const deck = promptDeck(base);
+    if (!deck) return;
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "update_deck", deck: deck }));
+}
+
+function getProfile() {
+    const uid = getUID();
+    if (!uid) {
+        alert("Please enter a UID");
+        return;
+    }
+    ws.send(JSON.stringify({ playerUid: uid, type: "get_profile" }));
+    ws.send(JSON.stringify({ playerUid: uid, type: "get_match_history" }));
+}
+
+function setDisplayName() {
+    const uid = getUID();
+    if (!uid) {
+        alert("Please enter a UID");
+        return;
+    }
+    const name = prompt("Display name:");
+    if (!name) return;
+    ws.send(JSON.stringify({ playerUid: uid, type: "set_display_name", message: name }));
+}
+
+function showProfile(profile, season, rating) {
+    const lines = [`${profile.displayName} (${profile.uid})`,
+        `Playing since ${new Date(profile.created).toLocaleDateString()}`,
+        `Record: ${profile.wins}W - ${profile.losses}L`,
+        `Season ${season} rating: ${rating.rating} (peak ${rating.peak}, ${rating.wins}W - ${rating.losses}L ranked)`];
+    for (const [deckId, rec] of Object.entries(profile.decks || {})) {
+        lines.push(`  ${rec.deckName || "Deck " + deckId}: ${rec.wins}W - ${rec.losses}L`);
+    }
+    document.getElementById("profile-info").textContent = lines.join("\n");
+}
+
+function getLeaderboard() {
+    ws.send(JSON.stringify({ type: "get_leaderboard" }));
+}
+
+function showLeaderboard(season, players) {
+    const lines = [`Season ${season} leaderboard`];
+    players.forEach((p, i) => lines.push(`${i + 1}. ${p.uid} - ${p.rating} (${p.wins}W - ${p.losses}L)`));
+    if (players.length === 0) lines.push("No ranked games yet");
+    document.getElementById("match-history").textContent = lines.join("\n");
+}
+
+function showMatchHistory(player, matches, results) {
+    const matchLines = results.map(r => {
+        const opp = r.players.find(uid => uid !== player);
+        return `${r.winner === player ? "Won" : "Lost"} best-of-${r.bestOf} match vs ${opp} ${r.wins[player] || 0}-${r.wins[opp] || 0}` +
+            (r.reason === "forfeit" ? " (forfeit)" : "");
+    });
+    const lines = matches.map(m => {
+        const me = m.players.find(p => p.uid === player) || {};
+        const opp = m.players.find(p => p.uid !== player) || {};
+        const result = m.winner === player ? "Won" : "Lost";
+        const change = m.ratingChanges && m.ratingChanges[player] !== undefined ? ` [ranked ${m.ratingChanges[player] >= 0 ? "+" : ""}${m.ratingChanges[player]}]` : "";
+        const game = m.matchId ? ` [match game ${m.gameNumber}]` : "";
+        return `${result} vs ${opp.uid} (${me.deckName} vs ${opp.deckName}) - ${m.reason}, ${m.turns} turns, ${Math.round(m.duration / 60)} min${change}${game}`;
+    });
+    const all = matchLines.concat(lines);
+    document.getElementById("match-history").textContent = all.length ? all.join("\n") : "No matches yet";
+}
+
+// Best-of-N matches
+function matchScoreText(data) {
+    const opp = Object.keys(data.wins || {}).find(uid => uid !== myUID);
+    return `Match ${(data.wins || {})[myUID] || 0}-${(data.wins || {})[opp] || 0} (best of ${data.bestOf})`;
+}
+
+// Between games: optionally swap cards with the sideboard, then (for the loser) pick who goes first
+function promptSideboard(deck) {
+    let next = null;
+    const sideboard = deck.sideboard || [];
+    if (sideboard.length && confirm(`Sideboard: ${formatCardList(sideboard)}\nSwap cards for the next game?`)) {
+        const mainDeck = prompt("Main deck for the next game (cards left out go to the sideboard):", formatCardList(deck.mainDeck));
+        const vault = mainDeck === null ? null : prompt("Vault for the next game:", formatCardList(deck.vault));
+        if (mainDeck !== null && vault !== null) {
+            next = { Leader: deck.leaderId, MainDeck: parseCardList(mainDeck), Vault: parseCardList(vault) };
+        }
+    }
+    ws.send(JSON.stringify({ type: "sideboard", deck: next }));
+
+    if (matchState && matchState.chooser === myUID) {
+        const opp = Object.keys(matchState.wins).find(uid => uid !== myUID);
+        const first = confirm("You lost the last game. Go first in the next game? (Cancel = opponent goes first)") ? myUID : opp;
+        ws.send(JSON.stringify({ type: "choose_first", targetPlayer: first }));
+    }
+}
+
+function showMatchWaiting(data) {
+    const waiting = [];
+    if (!data.firstPlayer) waiting.push(data.chooser === myUID ? "you to choose who goes first" : "opponent to choose who goes first");
+    for (const uid of Object.keys(data.wins)) {
+        if (!(data.ready || {})[uid]) waiting.push(uid === myUID ? "your sideboard" : "opponent's sideboard");
+    }
+    setTurnStatus(`${matchScoreText(data)} - waiting for ${waiting.join(", ")}`);
+}
+
+// Clear the board for the next game of a match, keeping the game screen open
+function resetBoardForNextGame() {
+    myHealth = 30;
+    opponentHealth = 30;
+    myHand = [];
+    myField = [];
+    myLands = [];
+    opponentField = [];
+    opponentLands = [];
+    myManaPool = { White: 0, Blue: 0, Black: 0, Red: 0, Green: 0, Colorless: 0 };
+    inDrawPhase = false;
+    document.getElementById("turn-status").textContent = "";
+    const buttons = document.querySelectorAll("#game-controls .section-content button:not(.hide-btn)");
+    buttons.forEach(btn => btn.disabled = false);
+    updateHealthDisplay();
+    renderField();
+    renderLands();
+    renderOpponentField();
+    renderOpponentLands();
+}
+
+function exportDeck() {
+    const deckId = getSelectedDeck();
+    if (!deckId) {
+        alert("Please select a deck");
+        return;
+    }
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "export_deck", deckId: deckId }));
+}
+
+function importDeck() {
+    const uid = getUID();
+    if (!uid) {
+        alert("Please enter a UID");
+        return;
+    }
+    const code = prompt("Paste a deck code:");
+    if (!code) return;
+    const name = prompt("Name for the imported deck:", "Imported deck");
+    if (name === null) return;
+    ws.send(JSON.stringify({ playerUid: uid, type: "import_deck", deckCode: code.trim(), deckName: name }));
+}
+
+function deleteDeck() {
+    const deck = selectedCustomDeck();
+    if (!deck) {
+        alert("Select one of your custom decks to delete");
+        return;
+    }
+    if (!confirm(`Delete deck "${deck.name}"?`)) return;
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "delete_deck", deckId: deck.id }));
 }
 
 function startGame() {
@@ -904,13 +1476,21 @@ function startGame() {
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "start_game",
-        deckId: selectedDeckId
+        deckId: selectedDeckId,
+        ranked: document.getElementById("ranked-checkbox").checked,
+        private: document.getElementById("private-checkbox").checked,
+        password: document.getElementById("lobby-password").value,
+        bestOf: parseInt(document.getElementById("best-of-select").value),
+        maxPlayers: parseInt(document.getElementById("players-select").value),
+        teams: document.getElementById("teams-checkbox").checked,
+        sharedLife: document.getElementById("shared-life-checkbox").checked,
+        ruleSet: document.getElementById("rule-set-select").value
     }));
     setStatus("Creating game...");
     showInGameLobby();
 }
 
-function joinGame() {
+function findMatch() {
     myUID = getUID();
     if (!myUID) {
         alert("Please enter a UID");
@@ -925,16 +1505,35 @@ function joinGame() {
 
     ws.send(JSON.stringify({
         playerUid: myUID,
-        type: "join_game",
-        deckId: selectedDeckId
+        type: "join_queue",
+        deckId: selectedDeckId,
+        format: document.getElementById("ranked-checkbox").checked ? "ranked" : "casual"
     }));
-    setStatus("Joining game...");
-    showInGameLobby();
+    setStatus("Joining queue...");
+}
+
+function cancelQueue() {
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "leave_queue" }));
+}
+
+function showQueueStatus(status) {
+    setStatus(`Searching for a ${status.format} match... ${status.waitSeconds}s ` +
+        `(${status.queueSize} queued, rating ${status.rating} \u00b1${status.searchRange})`);
+    document.getElementById("find-match-btn").style.display = "none";
+    document.getElementById("cancel-queue-btn").style.display = "inline-block";
+}
+
+function hideQueueStatus() {
+    document.getElementById("find-match-btn").style.display = "inline-block";
+    document.getElementById("cancel-queue-btn").style.display = "none";
 }
 
 function showInGameLobby() {
     document.getElementById("start-btn").style.display = "none";
+    document.getElementById("find-match-btn").style.display = "none";
+    document.getElementById("cancel-queue-btn").style.display = "none";
     document.getElementById("browse-btn").style.display = "none";
+    document.getElementById("invite-btn").style.display = "none";
     document.getElementById("deck-select-area").style.display = "none";
     document.getElementById("leave-btn").style.display = "inline-block";
     document.getElementById("game-list-container").style.display = "none";
@@ -943,21 +1542,36 @@ function showInGameLobby() {
 
 function showOutOfGameLobby() {
     document.getElementById("start-btn").style.display = "inline-block";
+    document.getElementById("find-match-btn").style.display = "inline-block";
     document.getElementById("browse-btn").style.display = "inline-block";
+    document.getElementById("invite-btn").style.display = "inline-block";
+    document.getElementById("lobby-host-controls").style.display = "none";
     document.getElementById("deck-select-area").style.display = "block";
     document.getElementById("leave-btn").style.display = "none";
     document.getElementById("game-list-container").style.display = "block";
 }
 
+// Auto-pay options sent with play actions
+function autoPayOptions() {
+    return {
+        autoPay: document.getElementById("auto-pay").checked,
+        burnLands: document.getElementById("auto-burn").checked
+    };
+}
+
 function playCard(cardId) {
     if (currentTurn !== myUID) {
         alert("Not your turn!");
         return;
     }
+    const costOptions = cardDB[cardId] ? chooseCostOptions(cardDB[cardId]) : {};
+    if (costOptions === null) return;
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "play_card",
-        cardId: cardId
+        cardId: cardId,
+        ...autoPayOptions(),
+        ...costOptions
     }));
 }
 
@@ -1006,13 +1620,34 @@ function burnCard(cardId) {
         alert("Not your turn!");
         return;
     }
+    const color = chooseLandColor(cardDB[cardId]);
+    if (color === null) return;
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "burn_card",
-        cardId: cardId
+        cardId: cardId,
+        color: color
     }));
 }
 
+// The mana a land can produce, one entry per choice
+function landManaOptions(card) {
+    if (!card) return [];
+    if (card.ManaChoices && card.ManaChoices.length > 0) return card.ManaChoices;
+    if (card.Provides && formatMana(card.Provides) !== "0") return [card.Provides];
+    return [];
+}
+
+// Asks which color to produce when a land has several choices
+// Returns "" when there's nothing to choose, or null if the player cancelled
+function chooseLandColor(card) {
+    const options = landManaOptions(card);
+    if (options.length <= 1) return "";
+    const input = prompt(`${card.Name} - choose mana: ${options.map(formatMana).join(", ")}\n(W, U, B, R, G or C)`);
+    if (input === null) return null;
+    return input.trim();
+}
+
 // Combat functions
 function toggleCombatMode() {
     if (currentTurn !== myUID) {
@@ -1135,11 +1770,64 @@ function formatCost(cost) {
     if (cost.Red) parts.push(cost.Red + "R");
     if (cost.Green) parts.push(cost.Green + "G");
     if (cost.Colorless) parts.push(cost.Colorless);
+    for (const h of (cost.Hybrid || [])) parts.push("{" + h + "}");
+    for (let i = 0; i < (cost.X || 0); i++) parts.push("X");
     return parts.join(" ") || "Free";
 }
 
+// Expands hybrid symbols into the fixed costs they allow (X counted as 0)
+function costAlternatives(cost) {
+    const letters = { W: "White", U: "Blue", B: "Black", R: "Red", G: "Green" };
+    let alts = [Object.assign({}, cost, { Hybrid: [], X: 0 })];
+    for (const h of (cost.Hybrid || [])) {
+        const next = [];
+        for (const alt of alts) {
+            for (const letter of h.toUpperCase().split("/")) {
+                const color = letters[letter.trim()];
+                if (!color) continue;
+                const c = Object.assign({}, alt);
+                c[color] = (c[color] || 0) + 1;
+                next.push(c);
+            }
+        }
+        alts = next;
+    }
+    return alts;
+}
+
+// Asks for X and an alternative cost when the card has them
+// Returns the extra action fields, or null if the player cancelled
+function chooseCostOptions(card) {
+    const options = {};
+    if (card.Cost && card.Cost.X) {
+        const input = prompt(`Choose a value for X (${card.Name}):`, "1");
+        if (input === null) return null;
+        options.x = Math.max(0, parseInt(input, 10) || 0);
+    }
+    if (card.AltCosts && card.AltCosts.length > 0) {
+        const lines = ["0: " + formatCost(card.Cost)].concat(
+            card.AltCosts.map((alt, i) => `${i + 1}: ${alt.Text || alt.Name}`));
+        const input = prompt("Choose how to pay:\n" + lines.join("\n"), "0");
+        if (input === null) return null;
+        const idx = parseInt(input, 10) || 0;
+        const alt = card.AltCosts[idx - 1];
+        if (alt) {
+            options.altCost = idx;
+            if (alt.Discard > 0) {
+                const ids = prompt(`Card IDs to discard (${alt.Discard}), comma-separated:`);
+                if (ids === null) return null;
+                options.discardIds = ids.split(",").map(x => parseInt(x, 10)).filter(x => !isNaN(x));
+            }
+        }
+    }
+    return options;
+}
+
 function canAffordCost(cost) {
     if (!cost) return true;
+    if (cost.Hybrid && cost.Hybrid.length > 0) {
+        return costAlternatives(cost).some(alt => canAffordCost(alt));
+    }
     const pool = myManaPool;
     // Check each colored mana requirement
     if ((cost.White || 0) > (pool.White || 0)) return false;
@@ -1159,16 +1847,44 @@ function refreshInstantAffordability() {
     for (const instant of myInstants) {
         const card = cardDB[instant.cardId];
         if (card) {
-            instant.canAfford = canAffordCost(card.Cost);
+            // The server's answer also counts untapped lands for auto-pay
+            const viaLands = document.getElementById("auto-pay").checked && instant.canAfford;
+            instant.canAfford = canAffordCost(card.Cost) || viaLands;
         }
     }
 }
 
+// Buttons for a field card's activated abilities
+function formatActivatedAbilities(fc, card) {
+    if (!card.ActivatedAbilities || card.ActivatedAbilities.length === 0) return '';
+    return card.ActivatedAbilities.map((ab, idx) =>
+        `<button class="ability-btn" style="font-size:9px;" onclick="event.stopPropagation(); activateAbility(${fc.instanceId}, ${idx})">${escapeHtml(ab.Text || ab.Name)}</button>`
+    ).join('');
+}
+
 function formatAbilities(abilities) {
     if (!abilities || abilities.length === 0) return '';
     return `<div class="card-abilities">${abilities.join(', ')}</div>`;
 }
 
+// Named statuses shown on field cards (Tapped/Summoned are rendered separately)
+const NAMED_STATUSES = ["Stunned", "Poisoned", "Shielded", "Frozen", "Silenced"];
+
+function formatStatuses(fc) {
+    if (!fc.status) return '';
+    const parts = [];
+    for (const name of NAMED_STATUSES) {
+        const stacks = fc.status[name] || 0;
+        if (stacks <= 0) continue;
+        let label = stacks > 1 ? `${name} x${stacks}` : name;
+        const turns = fc.statusTurns && fc.statusTurns[name];
+        if (turns) label += ` (${turns}t)`;
+        parts.push(label);
+    }
+    if (parts.length === 0) return '';
+    return `<div class="card-statuses" style="color:#9c27b0;font-size:10px;">${parts.join(', ')}</div>`;
+}
+
 function renderField() {
     const fieldEl = document.getElementById("field");
     fieldEl.innerHTML = "";
@@ -1201,9 +1917,9 @@ function renderField() {
             cardEl.style.cursor = "pointer";
         }
 
-        const effectiveAttack = (card?.Attack || 0) + (fc.damageModifier || 0);
+        const effectiveAttack = (card?.Attack || 0) + (fc.damageModifier || 0) + (fc.effectAttack || 0);
         const effectiveHealth = fc.currentHealth;
-        const maxHealth = (card?.Defense || 0) + (fc.healthModifier || 0);
+        const maxHealth = (card?.Defense || 0) + (fc.healthModifier || 0) + (fc.effectHealth || 0);
 
         if (card) {
             let statusText = '';
@@ -1226,6 +1942,8 @@ function renderField() {
                 <div class="card-attack">ATK: ${effectiveAttack}</div>
                 <div class="card-health">HP: ${effectiveHealth}/${maxHealth}</div>
                 ${abilitiesStr}
+                ${formatActivatedAbilities(fc, card)}
+                ${formatStatuses(fc)}
                 ${statusText}
             `;
         } else {
@@ -1268,10 +1986,42 @@ function tapLand(instanceId) {
         alert("Not your turn!");
         return;
     }
+    const fc = findFieldCard(myLands, instanceId);
+    const color = chooseLandColor(fc && cardDB[fc.cardId]);
+    if (color === null) return;
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "tap_card",
-        instanceId: instanceId
+        instanceId: instanceId,
+        color: color
+    }));
+}
+
+function activateAbility(instanceId, abilityIndex) {
+    const fc = findFieldCard(myField, instanceId) || findFieldCard(myLands, instanceId);
+    const card = fc && cardDB[fc.cardId];
+    const ability = card && card.ActivatedAbilities && card.ActivatedAbilities[abilityIndex];
+    if (!ability) return;
+
+    let targetId = 0;
+    if ((ability.Script || "").includes("'target'")) {
+        const input = prompt("Target creature instance ID:");
+        if (input === null) return;
+        targetId = parseInt(input, 10) || 0;
+    }
+    let x = 0;
+    if (ability.Cost && ability.Cost.X) {
+        const input = prompt(`Choose a value for X (${ability.Name}):`, "1");
+        if (input === null) return;
+        x = Math.max(0, parseInt(input, 10) || 0);
+    }
+    ws.send(JSON.stringify({
+        playerUid: myUID,
+        type: "activate_ability",
+        instanceId: instanceId,
+        abilityIndex: abilityIndex,
+        targetId: targetId,
+        x: x
     }));
 }
 
@@ -1279,6 +2029,21 @@ function findFieldCard(arr, instanceId) {
     return arr.find(fc => fc.instanceId === instanceId);
 }
 
+// Finds a creature on either side of the board and re-renders that side
+function updateAnyFieldCard(instanceId, update) {
+    let fc = findFieldCard(myField, instanceId);
+    if (fc) {
+        update(fc);
+        renderField();
+        return;
+    }
+    fc = findFieldCard(opponentField, instanceId);
+    if (fc) {
+        update(fc);
+        renderOpponentField();
+    }
+}
+
 function updateManaPoolDisplay() {
     const manaEl = document.getElementById("mana-display");
     if (manaEl) {
@@ -1291,12 +2056,12 @@ function getAvailableMana() {
     for (const fc of myLands) {
         const card = cardDB[fc.cardId];
         if (card && card.CardType === "Land") {
-            // Derive mana from land name
-            if (card.Name.includes("White")) mana.White++;
-            else if (card.Name.includes("Blue")) mana.Blue++;
-            else if (card.Name.includes("Black")) mana.Black++;
-            else if (card.Name.includes("Red")) mana.Red++;
-            else if (card.Name.includes("Green")) mana.Green++;
+            // Lands with choices count their first option
+            const option = landManaOptions(card)[0];
+            if (!option) continue;
+            for (const color of Object.keys(mana)) {
+                mana[color] += option[color] || 0;
+            }
         }
     }
     return mana;
@@ -1370,9 +2135,9 @@ function renderOpponentField() {
         //     }
         // }
 
-        const effectiveAttack = (card?.Attack || 0) + (fc.damageModifier || 0);
+        const effectiveAttack = (card?.Attack || 0) + (fc.damageModifier || 0) + (fc.effectAttack || 0);
         const effectiveHealth = fc.currentHealth;
-        const maxHealth = (card?.Defense || 0) + (fc.healthModifier || 0);
+        const maxHealth = (card?.Defense || 0) + (fc.healthModifier || 0) + (fc.effectHealth || 0);
 
         if (card) {
             let targetText = isTargeted ? '<div style="color:#ff5722;font-size:10px;">Targeted</div>' : '';
@@ -1384,6 +2149,7 @@ function renderOpponentField() {
                 <div class="card-attack">ATK: ${effectiveAttack}</div>
                 <div class="card-health">HP: ${effectiveHealth}/${maxHealth}</div>
                 ${abilitiesStr}
+                ${formatStatuses(fc)}
                 ${targetText}
             `;
         } else {
@@ -1424,9 +2190,14 @@ function renderLeaders() {
     if (myLeaderEl && myLeader) {
         const card = cardDB[myLeader];
         if (card) {
-            const costStr = formatCost(card.Cost);
+            const costStr = formatCost(card.Cost) + (myLeaderTax ? ` +${myLeaderTax} tax` : '');
             const abilitiesStr = formatAbilities(card.Abilities);
             const cardText = card.CardText ? `<div class="card-text">${card.CardText}</div>` : '';
+            let powerBtn = '';
+            if (card.HeroPower) {
+                const disabled = myLeaderPowerUsed ? 'disabled' : '';
+                powerBtn = `<button class="hero-power-btn" ${disabled} onclick="event.stopPropagation(); useLeaderPower()">${escapeHtml(card.HeroPower.Text || card.HeroPower.Name)}</button>`;
+            }
             myLeaderEl.innerHTML = `
                 <div class="leader-label">Leader</div>
                 <div class="card-name">${card.Name}</div>
@@ -1434,6 +2205,7 @@ function renderLeaders() {
                 <div class="card-stats">${card.Attack}/${card.Defense}</div>
                 ${cardText}
                 ${abilitiesStr}
+                ${powerBtn}
             `;
             myLeaderEl.style.cursor = "pointer";
             myLeaderEl.onclick = () => playLeader();
@@ -1449,7 +2221,7 @@ function renderLeaders() {
     if (opponentLeaderEl && opponentLeader) {
         const card = cardDB[opponentLeader];
         if (card) {
-            const costStr = formatCost(card.Cost);
+            const costStr = formatCost(card.Cost) + (opponentLeaderTax ? ` +${opponentLeaderTax} tax` : '');
             const abilitiesStr = formatAbilities(card.Abilities);
             const cardText = card.CardText ? `<div class="card-text">${card.CardText}</div>` : '';
             opponentLeaderEl.innerHTML = `
@@ -1479,7 +2251,29 @@ function playLeader() {
     }
     ws.send(JSON.stringify({
         playerUid: myUID,
-        type: "play_leader"
+        type: "play_leader",
+        ...autoPayOptions()
+    }));
+}
+
+function useLeaderPower() {
+    if (currentTurn !== myUID) {
+        alert("Not your turn!");
+        return;
+    }
+    const card = cardDB[myLeader];
+    if (!card || !card.HeroPower) return;
+
+    let targetId = 0;
+    if ((card.HeroPower.Script || "").includes("'target'")) {
+        const input = prompt("Target creature instance ID:");
+        if (input === null) return;
+        targetId = parseInt(input, 10) || 0;
+    }
+    ws.send(JSON.stringify({
+        playerUid: myUID,
+        type: "use_leader_power",
+        targetId: targetId
     }));
 }
 
@@ -1548,6 +2342,162 @@ function selectOpponentAsTarget() {
     selectTarget("player", 0, opponentUID);
 }
 
+// ============================================================================
+// FREE-FOR-ALL
+// ============================================================================
+
+// setupOpponents records every opponent's board at the start of a game or on reconnect
+// and shows the one after us in turn order (or focus, if given)
+function setupOpponents(players, order, focus) {
+    turnOrder = order || Object.keys(players);
+    opponentBoards = {};
+    for (const uid of turnOrder) {
+        if (uid === myUID) continue;
+        const p = players[uid] || {};
+        opponentBoards[uid] = {
+            health: p.life === undefined ? 30 : p.life,
+            field: p.field || [],
+            lands: p.lands || [],
+            leader: p.leader || 0,
+            leaderTax: p.leaderTax || 0,
+            eliminated: p.eliminated || false
+        };
+    }
+    if (!focus || !opponentBoards[focus]) {
+        const me = turnOrder.indexOf(myUID);
+        focus = turnOrder.find((uid, i) => i > me && opponentBoards[uid]) || Object.keys(opponentBoards)[0];
+    }
+    window.opponentUID = undefined;
+    loadBoard(focus);
+}
+
+function saveBoard() {
+    const board = opponentBoards[window.opponentUID];
+    if (!board) return;
+    board.health = opponentHealth;
+    board.field = opponentField;
+    board.lands = opponentLands;
+    board.leader = opponentLeader;
+    board.leaderTax = opponentLeaderTax;
+}
+
+function loadBoard(uid) {
+    const board = opponentBoards[uid];
+    if (!board) return;
+    window.opponentUID = uid;
+    opponentHealth = board.health;
+    opponentField = board.field;
+    opponentLands = board.lands;
+    opponentLeader = board.leader;
+    opponentLeaderTax = board.leaderTax;
+}
+
+// eventOpponent returns the opponent an event is about, going by the players
+// it names or else the permanent it touches
+function eventOpponent(event) {
+    const data = event.data || {};
+    for (const key of ["targetPlayer", "target", "player", "owner"]) {
+        if (opponentBoards[data[key]]) return data[key];
+    }
+    const id = data.targetInstanceId !== undefined ? data.targetInstanceId : data.instanceId;
+    if (id === undefined) return null;
+    for (const [uid, board] of Object.entries(opponentBoards)) {
+        if (board.field.some(fc => fc.instanceId === id) || board.lands.some(fc => fc.instanceId === id)) return uid;
+    }
+    return null;
+}
+
+// showBoardFor swaps in the board of the opponent an event is about while it's handled
+// Returns the opponent that was on screen so restoreBoard can put them back.
+function showBoardFor(event) {
+    const shown = window.opponentUID;
+    const other = eventOpponent(event);
+    if (!other || other === shown) return null;
+    saveBoard();
+    loadBoard(other);
+    return shown;
+}
+
+function restoreBoard(shown) {
+    if (!shown) return;
+    saveBoard();
+    loadBoard(shown);
+    renderOpponentBoard();
+}
+
+function isTeammate(uid) {
+    return teams !== null && uid !== myUID && teams[uid] === teams[myUID];
+}
+
+// shareLife copies a life change to the rest of the player's team when teammates share life
+function shareLife(event) {
+    const data = event.data || {};
+    if (!sharedLife || !teams || data.newLife === undefined) return;
+    const uid = data.targetPlayer || data.target || data.player;
+    if (teams[uid] === undefined) return;
+    for (const mate of Object.keys(teams)) {
+        if (mate === uid || teams[mate] !== teams[uid]) continue;
+        if (mate === myUID) {
+            myHealth = data.newLife;
+        } else if (mate === window.opponentUID) {
+            opponentHealth = data.newLife;
+        } else if (opponentBoards[mate]) {
+            opponentBoards[mate].health = data.newLife;
+        }
+    }
+    updateHealthDisplay();
+}
+
+function renderOpponentBoard() {
+    updateHealthDisplay();
+    renderOpponentField();
+    renderOpponentLands();
+    renderLeaders();
+}
+
+// focusOpponent shows another opponent's board - attacks go to whoever is shown
+function focusOpponent(uid) {
+    if (!opponentBoards[uid] || uid === window.opponentUID) return;
+    saveBoard();
+    loadBoard(uid);
+    renderOpponentBoard();
+    renderOpponentList();
+}
+
+// renderOpponentList lists every opponent with their life once there's more than one
+function renderOpponentList() {
+    const list = document.getElementById("opponent-list");
+    saveBoard();
+    const uids = Object.keys(opponentBoards);
+    if (uids.length < 2) {
+        list.innerHTML = "";
+        return;
+    }
+    list.innerHTML = "";
+    for (const uid of turnOrder.filter(u => opponentBoards[u])) {
+        const board = opponentBoards[uid];
+        const btn = document.createElement("button");
+        btn.textContent = `${uid}${isTeammate(uid) ? " (ally)" : ""}: ${board.eliminated ? "out" : board.health}` + (uid === currentTurn ? " *" : "");
+        btn.disabled = board.eliminated;
+        if (uid === window.opponentUID) btn.style.fontWeight = "bold";
+        btn.onclick = () => focusOpponent(uid);
+        list.appendChild(btn);
+    }
+}
+
+// populateRuleSetSelect lists the server's rule set presets for new games
+function populateRuleSetSelect(ruleSets) {
+    const select = document.getElementById("rule-set-select");
+    select.innerHTML = "";
+    for (const rules of ruleSets) {
+        const option = document.createElement("option");
+        option.value = rules.name;
+        option.textContent = rules.name;
+        option.title = rules.description || "";
+        select.appendChild(option);
+    }
+}
+
 function getOpponentUID() {
     // We need to track opponent UID - for now derive from event or default
     return window.opponentUID || "opponent";
@@ -1591,6 +2541,10 @@ function leaveGame() {
     myManaPool = { White: 0, Blue: 0, Black: 0, Red: 0, Green: 0, Colorless: 0 };
     myLeader = 0;
     opponentLeader = 0;
+    turnOrder = [];
+    opponentBoards = {};
+    teams = null;
+    sharedLife = false;
 
     // Reset UI
     hideMulliganUI();
@@ -1601,6 +2555,7 @@ function leaveGame() {
     document.getElementById("my-health").textContent = "30";
     document.getElementById("opponent-health").textContent = "30";
     document.getElementById("turn-status").textContent = "";
+    document.getElementById("opponent-list").innerHTML = "";
     document.getElementById("hand").innerHTML = "";
     document.getElementById("field").innerHTML = "";
     document.getElementById("lands").innerHTML = "";
@@ -1635,7 +2590,8 @@ function sendChat() {
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "chat",
-        message: message
+        message: message,
+        channel: document.getElementById("chat-channel").value
     }));
 
     input.value = "";
@@ -1673,6 +2629,12 @@ function refreshGameList() {
     ws.send(JSON.stringify({ type: "list_games" }));
 }
 
+function joinLockedGame(gameIdToJoin) {
+    const password = prompt("Password:");
+    if (password === null) return;
+    joinSpecificGame(gameIdToJoin, { password: password });
+}
+
 function displayGameList(games) {
     const listEl = document.getElementById("game-list");
     if (!listEl) return;
@@ -1688,7 +2650,7 @@ function displayGameList(games) {
         gameEl.className = "game-item";
 
         const playerList = game.players.join(", ") || "Empty";
-        const status = game.started ? "In Progress" : `Waiting (${game.playerCount}/2)`;
+        const status = (game.ranked ? "Ranked - " : "") + (game.started ? "In Progress" : `Waiting (${game.playerCount}/2)`);
         const statusClass = game.started ? "game-status in-progress" : "game-status";
         const canJoin = !game.started && game.playerCount < 2;
 
@@ -1698,13 +2660,42 @@ function displayGameList(games) {
                 <span class="${statusClass}">${status}</span>
             </div>
             <div class="game-players">Players: ${playerList}</div>
-            ${canJoin ? `<button onclick="joinSpecificGame('${game.gameId}')">Join</button>` : ''}
+            ${canJoin ? `<button onclick="${game.locked ? `joinLockedGame('${game.gameId}')` : `joinSpecificGame('${game.gameId}')`}">${game.locked ? "Join (password)" : "Join"}</button>` : ''}
         `;
         listEl.appendChild(gameEl);
     }
 }
 
-function joinSpecificGame(gameIdToJoin) {
+// Shows who's in a private lobby; the host gets Start and Kick buttons
+function showLobbyPlayers(lobby) {
+    const others = lobby.players.filter(uid => uid !== lobby.host);
+    const controls = document.getElementById("lobby-host-controls");
+    if (lobby.host !== myUID) {
+        setStatus(`Joined ${lobby.host}'s game - waiting for the host to start`);
+        return;
+    }
+    setStatus(others.length ? `${others.join(", ")} joined - start when ready` : "Waiting for opponent to join...");
+    controls.innerHTML = "";
+    controls.style.display = others.length ? "block" : "none";
+    const start = document.createElement("button");
+    start.textContent = "Start Game";
+    start.onclick = () => ws.send(JSON.stringify({ playerUid: myUID, type: "begin_game" }));
+    controls.appendChild(start);
+    for (const uid of others) {
+        const kick = document.createElement("button");
+        kick.textContent = `Kick ${uid}`;
+        kick.onclick = () => ws.send(JSON.stringify({ playerUid: myUID, type: "kick_player", targetPlayer: uid }));
+        controls.appendChild(kick);
+    }
+}
+
+function joinByInviteCode() {
+    const code = prompt("Invite code:");
+    if (!code) return;
+    joinSpecificGame("", { inviteCode: code.trim() });
+}
+
+function joinSpecificGame(gameIdToJoin, secret) {
     myUID = getUID();
     if (!myUID) {
         alert("Please enter a UID");
@@ -1721,7 +2712,8 @@ function joinSpecificGame(gameIdToJoin) {
         playerUid: myUID,
         type: "join_specific_game",
         gameId: gameIdToJoin,
-        deckId: selectedDeckId
+        deckId: selectedDeckId,
+        ...(secret || {})
     }));
     setStatus("Joining game...");
     showInGameLobby();
@@ -1878,11 +2870,15 @@ function playInstant(targetInstanceId) {
         alert("Select an instant first!");
         return;
     }
+    const costOptions = cardDB[selectedInstant] ? chooseCostOptions(cardDB[selectedInstant]) : {};
+    if (costOptions === null) return;
     ws.send(JSON.stringify({
         playerUid: myUID,
         type: "play_instant",
         cardId: selectedInstant,
-        instanceId: targetInstanceId
+        instanceId: targetInstanceId,
+        ...autoPayOptions(),
+        ...costOptions
     }));
     selectedInstant = null;
     updateResponseUI();
@@ -1898,3 +2894,127 @@ function passPriority() {
         type: "pass_priority"
     }));
 }
+
+// ==================== DRAFT & SEALED ====================
+
+function createDraft() {
+    const uid = getUID();
+    if (!uid) { alert("Enter a UID first"); return; }
+    ws.send(JSON.stringify({
+        playerUid: uid,
+        type: "create_draft",
+        format: document.getElementById("draft-format-select").value,
+        maxPlayers: parseInt(document.getElementById("draft-seats-select").value)
+    }));
+}
+
+function joinDraft() {
+    const uid = getUID();
+    const draftId = document.getElementById("draft-id").value.trim();
+    if (!uid || !draftId) { alert("Enter a UID and a draft ID"); return; }
+    ws.send(JSON.stringify({ playerUid: uid, type: "join_draft", draftId: draftId }));
+}
+
+function startDraft() {
+    if (!myDraft) return;
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "start_draft", draftId: myDraft.draftId }));
+}
+
+function leaveDraft() {
+    if (!myDraft) return;
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "leave_draft" }));
+    myDraft = null;
+    renderDraft();
+}
+
+function draftPick(cardId) {
+    ws.send(JSON.stringify({ playerUid: getUID(), type: "draft_pick", cardId: cardId }));
+}
+
+function buildDraftDeck() {
+    if (!myDraft) return;
+    const leader = parseInt(document.getElementById("draft-leader-select").value);
+    const mainDeck = [];
+    for (const box of document.querySelectorAll("#draft-pool input:checked")) {
+        mainDeck.push(parseInt(box.value));
+    }
+    if (!leader) { alert("Choose a leader from your pool"); return; }
+    ws.send(JSON.stringify({
+        playerUid: getUID(),
+        type: "build_draft_deck",
+        deck: {
+            Name: document.getElementById("draft-deck-name").value.trim(),
+            Leader: leader,
+            MainDeck: mainDeck
+        }
+    }));
+}
+
+function renderDraft() {
+    const status = document.getElementById("draft-status");
+    const packEl = document.getElementById("draft-pack");
+    const buildEl = document.getElementById("draft-build");
+    packEl.innerHTML = "";
+    buildEl.style.display = "none";
+    document.getElementById("draft-start-btn").style.display = "none";
+    document.getElementById("draft-leave-btn").style.display = myDraft ? "inline-block" : "none";
+    if (!myDraft) {
+        status.textContent = "";
+        return;
+    }
+
+    const d = myDraft;
+    const seats = d.seats.map(s => s.bot ? `${s.uid} (bot)` : s.uid).join(", ");
+    if (d.phase === "lobby") {
+        status.textContent = `Draft ${d.draftId} (${d.format}) - ${d.seats.length}/${d.size} seated: ${seats}`;
+        if (d.host === getUID()) document.getElementById("draft-start-btn").style.display = "inline-block";
+        return;
+    }
+    if (d.phase === "drafting") {
+        status.textContent = d.pack.length > 0
+            ? `Round ${d.round} of ${d.rounds} - pick a card (${d.picks.length} picked)`
+            : `Round ${d.round} of ${d.rounds} - waiting for the next pack (${d.picks.length} picked)`;
+        for (const cardId of d.pack) {
+            const card = cardDB[cardId];
+            const cardEl = document.createElement("div");
+            cardEl.className = "card";
+            cardEl.onclick = () => draftPick(cardId);
+            cardEl.innerHTML = card
+                ? `<div class="card-name">${card.Name}</div><div class="card-cost">${formatCost(card.Cost)}</div>` +
+                  `<div class="card-type">${card.CardType}${card.Rarity ? " - " + card.Rarity : ""}</div>` +
+                  (card.CardType === "Creature" ? `<div class="card-stats">${card.Attack}/${card.Defense}</div>` : "")
+                : `<div class="card-name">Card #${cardId}</div>`;
+            packEl.appendChild(cardEl);
+        }
+        return;
+    }
+
+    const mySeat = d.seats.find(s => s.uid === getUID());
+    if (mySeat && mySeat.built) {
+        status.textContent = "Your deck is built - pick it from your decks to play";
+        return;
+    }
+
+    // Building: choose a leader and the main deck from the pool, lands are added for you
+    status.textContent = `Build a deck from your ${d.picks.length} cards - a vault of lands is added to match its colors`;
+    buildEl.style.display = "block";
+    document.getElementById("draft-pick-count").textContent = d.picks.length;
+    const leaderSelect = document.getElementById("draft-leader-select");
+    const poolEl = document.getElementById("draft-pool");
+    leaderSelect.innerHTML = "";
+    poolEl.innerHTML = "";
+    const sorted = [...d.picks].sort((a, b) => ((cardDB[a] || {}).Name || "").localeCompare((cardDB[b] || {}).Name || ""));
+    for (const cardId of sorted) {
+        const name = cardDB[cardId] ? cardDB[cardId].Name : `Card #${cardId}`;
+        const option = document.createElement("option");
+        option.value = cardId;
+        option.textContent = name;
+        leaderSelect.appendChild(option);
+
+        const label = document.createElement("label");
+        label.style.display = "inline-block";
+        label.style.width = "220px";
+        label.innerHTML = `<input type="checkbox" value="${cardId}" checked> ${name}`;
+        poolEl.appendChild(label);
+    }
+}
@@ -0,0 +1,9 @@
+// Package client holds the web client, embedded into the server binary
+package client
+
+import "embed"
+
+// Files are the client's static files, served by the server unless it runs in dev mode
+//
+//go:embed index.html client.js
+var Files embed.FS
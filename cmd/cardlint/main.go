@@ -0,0 +1,33 @@
+// cardlint checks card and deck data files for problems the JSON parser can't catch
+//
+// Usage: go run ./cmd/cardlint [-cards data/cards.json] [-decks data/decks.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"card-game/game"
+)
+
+func main() {
+	cardsPath := flag.String("cards", "data/cards.json", "path to the cards file")
+	decksPath := flag.String("decks", "data/decks.json", "path to the decks file")
+	flag.Parse()
+
+	problems, err := game.ValidateFiles(*cardsPath, *decksPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	for _, p := range problems {
+		fmt.Println(p.Error())
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(problems))
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
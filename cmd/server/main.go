@@ -1,33 +1,136 @@
 package main
 
 import (
-    "log"
+    "context"
+    "errors"
+    "flag"
+    "log/slog"
     "net/http"
+    "os"
+    "os/signal"
+    "syscall"
 
+    "card-game/config"
     "card-game/game"
     "card-game/server"
 )
 
 func main() {
+    cfg, err := config.Load(os.Args[1:])
+    if errors.Is(err, flag.ErrHelp) {
+        return
+    }
+    if err != nil {
+        fatal("bad configuration", "err", err)
+    }
+    level, _ := cfg.SlogLevel()
+    slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+    game.Manager = game.NewGameManager(game.ManagerConfig{
+        DisconnectTimeout: cfg.DisconnectTimeout,
+        InactivityTimeout: cfg.InactivityTimeout,
+        CleanupInterval:   cfg.CleanupInterval,
+    })
+    server.GameHub = server.NewHub(server.HubConfig{AllowedOrigins: cfg.AllowedOrigins})
+    game.PlayerDecks = game.NewDeckStore(cfg.PlayerDecksDir())
+    game.Profiles = game.NewProfileStore(cfg.ProfilesDir())
+
+    // Load rule set presets first: the standard preset sets the deck building limits
+    if err := game.LoadRuleSets(cfg.RuleSetsFile()); err != nil {
+        fatal("failed to load rule sets", "err", err)
+    }
+    slog.Info("loaded rule sets", "count", len(game.RuleSetDB))
+
     // Load cards and decks
-    if err := game.LoadCards("data/cards.json"); err != nil {
-        log.Fatal("Failed to load cards:", err)
+    if err := game.LoadCards(cfg.CardsFile()); err != nil {
+        fatal("failed to load cards", "err", err)
+    }
+    slog.Info("loaded cards", "count", len(game.CardDB))
+
+    if err := game.LoadDecks(cfg.DecksFile()); err != nil {
+        fatal("failed to load decks", "err", err)
     }
-    log.Printf("Loaded %d cards", len(game.CardDB))
+    slog.Info("loaded decks", "count", len(game.DeckDB))
 
-    if err := game.LoadDecks("data/decks.json"); err != nil {
-        log.Fatal("Failed to load decks:", err)
+    // Run the same data checks as cmd/cardlint and refuse to start on problems
+    problems, err := game.ValidateFiles(cfg.CardsFile(), cfg.DecksFile())
+    if err != nil {
+        fatal("failed to validate data", "err", err)
+    }
+    for _, p := range problems {
+        slog.Error("data problem", "problem", p.Error())
+    }
+    if len(problems) > 0 {
+        fatal("data problems found, fix them or run go run ./cmd/cardlint", "count", len(problems))
     }
-    log.Printf("Loaded %d decks", len(game.DeckDB))
+
+    if err := game.PlayerDecks.Load(); err != nil {
+        fatal("failed to load player decks", "err", err)
+    }
+    if err := game.Profiles.Load(); err != nil {
+        fatal("failed to load profiles", "err", err)
+    }
+
+    // SIGINT, SIGTERM or an admin restart starts a graceful shutdown
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    // Games keep timing out turns and being cleaned up while shutdown waits for them,
+    // so these stop once shutdown is over rather than at the signal
+    background, stopBackground := context.WithCancel(context.Background())
+    defer stopBackground()
 
     // Start background cleanup routine for stale games
-    game.Manager.StartCleanupRoutine()
+    game.Manager.StartCleanupRoutine(background)
+
+    // Pair queued players in the background
+    server.StartMatchmaking(ctx)
 
-    router := server.NewRouter()
+    // End turns that run past their rule set's timer
+    server.StartTurnTimer(background)
 
-    log.Println("Server running on :8080")
-    err := http.ListenAndServe(":8080", router)
+    router, err := server.NewRouter(server.AdminConfig{
+        Token:     cfg.AdminToken,
+        AuditFile: cfg.AdminAuditFile(),
+        CardsFile: cfg.CardsFile(),
+        DecksFile: cfg.DecksFile(),
+        Restart:   stop,
+    }, server.ClientConfig{
+        Dev: cfg.Dev,
+        Dir: cfg.ClientDir,
+    })
     if err != nil {
-        log.Fatal(err)
+        fatal("failed to load the web client", "err", err)
     }
+    if cfg.Dev {
+        slog.Info("dev mode: serving the web client from disk", "dir", cfg.ClientDir)
+    }
+
+    srv := &http.Server{Addr: cfg.Addr, Handler: router}
+    go func() {
+        var err error
+        if cfg.TLS() {
+            slog.Info("server running", "addr", cfg.Addr, "tls", true)
+            err = srv.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+        } else {
+            slog.Info("server running", "addr", cfg.Addr)
+            err = srv.ListenAndServe()
+        }
+        if !errors.Is(err, http.ErrServerClosed) {
+            fatal("server failed", "err", err)
+        }
+    }()
+
+    <-ctx.Done()
+    stop() // A second signal kills the server straight away
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+    defer cancel()
+    server.Shutdown(shutdownCtx, srv)
+    stopBackground()
+}
+
+// fatal logs at error level, which every log level shows, and exits
+func fatal(msg string, args ...any) {
+    slog.Error(msg, args...)
+    os.Exit(1)
 }
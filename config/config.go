@@ -0,0 +1,285 @@
+// Package config loads the server settings from defaults, an optional JSON file,
+// environment variables and command line flags, in that order of precedence.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds every server setting
+type Config struct {
+	Addr    string // Listen address
+	TLSCert string // Certificate and key files; both set serves HTTPS
+	TLSKey  string
+	DataDir string // Cards, decks, rule sets, player decks, profiles and the audit log
+
+	DisconnectTimeout time.Duration // Remove a game once every player has been disconnected this long
+	InactivityTimeout time.Duration // Remove a started game after this long without an action
+	CleanupInterval   time.Duration // How often stale games are looked for
+	ShutdownTimeout   time.Duration // How long a shutdown waits for running games before closing connections
+
+	AllowedOrigins []string // Origins allowed to open a WebSocket ("*" = any)
+	LogLevel       string   // debug, info, warn or error
+
+	Dev       bool // Serve the web client from ClientDir instead of the embedded copy
+	ClientDir string
+
+	AdminToken string // Enables the admin API; from the file or environment only, never a flag
+}
+
+// Default returns the settings used when nothing overrides them
+func Default() Config {
+	return Config{
+		Addr:              ":8080",
+		DataDir:           "data",
+		DisconnectTimeout: 1 * time.Minute,
+		InactivityTimeout: 5 * time.Minute,
+		CleanupInterval:   30 * time.Second,
+		ShutdownTimeout:   30 * time.Second,
+		AllowedOrigins:    []string{"*"},
+		LogLevel:          "info",
+		ClientDir:         "client",
+	}
+}
+
+// MinAdminTokenLength is the shortest admin token accepted
+const MinAdminTokenLength = 16
+
+// How a setting is given on the command line
+const (
+	valueFlag = iota // -name value
+	boolFlag         // -name, or -name=false
+	noFlag           // Secrets stay out of the process list
+)
+
+// setting is one configurable value: its flag and file key, environment variable and parser
+type setting struct {
+	name  string
+	env   string
+	usage string
+	flag  int
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"addr", "CARDGAME_ADDR", "listen address", valueFlag, func(c *Config, v string) error { c.Addr = v; return nil }},
+	{"tls-cert", "CARDGAME_TLS_CERT", "TLS certificate file", valueFlag, func(c *Config, v string) error { c.TLSCert = v; return nil }},
+	{"tls-key", "CARDGAME_TLS_KEY", "TLS key file", valueFlag, func(c *Config, v string) error { c.TLSKey = v; return nil }},
+	{"data-dir", "CARDGAME_DATA_DIR", "data directory", valueFlag, func(c *Config, v string) error { c.DataDir = v; return nil }},
+	{"disconnect-timeout", "CARDGAME_DISCONNECT_TIMEOUT", "remove games whose players are all disconnected this long", valueFlag, durationSetter(func(c *Config) *time.Duration { return &c.DisconnectTimeout })},
+	{"inactivity-timeout", "CARDGAME_INACTIVITY_TIMEOUT", "remove started games idle this long", valueFlag, durationSetter(func(c *Config) *time.Duration { return &c.InactivityTimeout })},
+	{"cleanup-interval", "CARDGAME_CLEANUP_INTERVAL", "how often stale games are removed", valueFlag, durationSetter(func(c *Config) *time.Duration { return &c.CleanupInterval })},
+	{"shutdown-timeout", "CARDGAME_SHUTDOWN_TIMEOUT", "how long a shutdown waits for running games", valueFlag, durationSetter(func(c *Config) *time.Duration { return &c.ShutdownTimeout })},
+	{"allowed-origins", "CARDGAME_ALLOWED_ORIGINS", "comma-separated WebSocket origins (* = any)", valueFlag, func(c *Config, v string) error { c.AllowedOrigins = splitList(v); return nil }},
+	{"log-level", "CARDGAME_LOG_LEVEL", "debug, info, warn or error", valueFlag, func(c *Config, v string) error { c.LogLevel = strings.ToLower(v); return nil }},
+	{"admin-token", "CARDGAME_ADMIN_TOKEN", "admin API token", noFlag, func(c *Config, v string) error { c.AdminToken = strings.TrimSpace(v); return nil }},
+	{"dev", "CARDGAME_DEV", "serve the web client from disk for live editing", boolFlag, func(c *Config, v string) (err error) { c.Dev, err = strconv.ParseBool(v); return err }},
+	{"client-dir", "CARDGAME_CLIENT_DIR", "web client directory served in dev mode", valueFlag, func(c *Config, v string) error { c.ClientDir = v; return nil }},
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, v string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func splitList(v string) []string {
+	list := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Load builds the configuration from args (without the program name) and validates it
+// A config file is read from -config or CARDGAME_CONFIG; environment variables override it and flags override both.
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CARDGAME_CONFIG"), "JSON config file")
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		if s.flag == noFlag {
+			continue
+		}
+		usage := s.usage + " (env " + s.env + ")"
+		if s.flag == boolFlag {
+			fs.BoolFunc(s.name, usage, func(v string) error {
+				flagValues[s.name] = v
+				return nil
+			})
+			continue
+		}
+		fs.Func(s.name, usage, func(v string) error {
+			flagValues[s.name] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return cfg, fmt.Errorf("config file %s: %v", *configPath, err)
+		}
+	}
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(&cfg, v); err != nil {
+				return cfg, fmt.Errorf("%s: %v", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if v, ok := flagValues[s.name]; ok {
+			if err := s.set(&cfg, v); err != nil {
+				return cfg, fmt.Errorf("-%s: %v", s.name, err)
+			}
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// loadFile applies a JSON object keyed by setting name, e.g. {"addr": ":8443", "allowed-origins": ["https://example.com"]}
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	for key, raw := range values {
+		s := findSetting(key)
+		if s == nil {
+			return fmt.Errorf("unknown setting %q", key)
+		}
+		var value string
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case []interface{}:
+			items := []string{}
+			for _, item := range v {
+				str, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("%s: list items must be strings", key)
+				}
+				items = append(items, str)
+			}
+			value = strings.Join(items, ",")
+		default:
+			return fmt.Errorf("%s: value must be a string, list or boolean", key)
+		}
+		if err := s.set(c, value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func findSetting(name string) *setting {
+	for i := range settings {
+		if settings[i].name == name {
+			return &settings[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks the settings make sense before the server starts with them
+func (c *Config) Validate() error {
+	switch {
+	case c.Addr == "":
+		return fmt.Errorf("missing listen address")
+	case (c.TLSCert == "") != (c.TLSKey == ""):
+		return fmt.Errorf("TLS needs both a certificate and a key")
+	case c.DisconnectTimeout <= 0 || c.InactivityTimeout <= 0 || c.CleanupInterval <= 0 || c.ShutdownTimeout <= 0:
+		return fmt.Errorf("timeouts and the cleanup interval must be above 0")
+	case c.AdminToken != "" && len(c.AdminToken) < MinAdminTokenLength:
+		return fmt.Errorf("the admin token must be at least %d characters", MinAdminTokenLength)
+	}
+	for _, path := range []string{c.TLSCert, c.TLSKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("TLS: %v", err)
+		}
+	}
+	if info, err := os.Stat(c.DataDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("data directory %q not found", c.DataDir)
+	}
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("no allowed origins (use * for any)")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("allowed origin %q must look like https://example.com", origin)
+		}
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		return err
+	}
+	if c.Dev {
+		if _, err := os.Stat(filepath.Join(c.ClientDir, "index.html")); err != nil {
+			return fmt.Errorf("dev mode: %v", err)
+		}
+	}
+	return nil
+}
+
+// SlogLevel converts the log level setting
+func (c *Config) SlogLevel() (slog.Level, error) {
+	switch c.LogLevel {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", c.LogLevel)
+}
+
+// TLS reports whether the server should serve HTTPS
+func (c *Config) TLS() bool {
+	return c.TLSCert != ""
+}
+
+// Paths of the files and directories under the data directory
+func (c *Config) CardsFile() string      { return filepath.Join(c.DataDir, "cards.json") }
+func (c *Config) DecksFile() string      { return filepath.Join(c.DataDir, "decks.json") }
+func (c *Config) RuleSetsFile() string   { return filepath.Join(c.DataDir, "rulesets.json") }
+func (c *Config) PlayerDecksDir() string { return filepath.Join(c.DataDir, "player_decks") }
+func (c *Config) ProfilesDir() string    { return filepath.Join(c.DataDir, "profiles") }
+func (c *Config) AdminAuditFile() string { return filepath.Join(c.DataDir, "admin_audit.log") }
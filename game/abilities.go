@@ -0,0 +1,129 @@
+// abilities.go - Activated abilities on permanents
+package game
+
+// activateAbility handles using an activated ability of a permanent on the field
+// a.InstanceID is the permanent, a.AbilityIndex the ability, a.TargetID an optional target creature,
+// a.X the value chosen for an {X} cost
+func (g *Game) activateAbility(a Action) []Event {
+	player := g.Players[a.PlayerUID]
+
+	var source *FieldCard
+	for _, fc := range player.Field {
+		if fc.InstanceID == a.InstanceID {
+			source = fc
+			break
+		}
+	}
+	if source == nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card not found on field"}}}
+	}
+
+	card := g.Card(source.CardID)
+	if a.AbilityIndex < 0 || a.AbilityIndex >= len(card.ActivatedAbilities) {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message":      "Card has no such ability",
+			"abilityIndex": a.AbilityIndex,
+		}}}
+	}
+	ability := card.ActivatedAbilities[a.AbilityIndex]
+
+	if source.HasStatus(StatusSilenced) {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card is silenced"}}}
+	}
+
+	if ability.Tap {
+		if source.IsTapped() {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card is already tapped"}}}
+		}
+		if card.CardType == "Creature" && source.IsSummoned() {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card has summoning sickness"}}}
+		}
+		if source.HasStatus(StatusStunned) {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card is stunned"}}}
+		}
+	}
+
+	if a.TargetID == 0 && a.TargetPlayer == "" && scriptNeedsTarget(ability.Script) {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Ability requires a target"}}}
+	}
+
+	// Find target creature
+	var targetCreature *FieldCard
+	if a.TargetID != 0 {
+		targetCreature, _ = g.findFieldCard(a.TargetID)
+		if targetCreature == nil {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Target creature not found"}}}
+		}
+	}
+
+	// Pay costs, with X filled in like a spell's (a stray X is dropped so the script never sees it)
+	if a.X < 0 {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "X cannot be negative"}}}
+	}
+	if ability.Cost.X == 0 {
+		a.X = 0
+	}
+	cost := ability.Cost.WithX(a.X)
+	if err := player.payCost(cost, a.Payment, 0); err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message":   err.Error(),
+			"required":  cost,
+			"available": player.ManaPool,
+		}}}
+	}
+
+	events := []Event{}
+	if ability.Tap {
+		source.SetTapped(true)
+		events = append(events, Event{
+			Type: "CardTapped",
+			Data: map[string]interface{}{
+				"player":     a.PlayerUID,
+				"instanceId": source.InstanceID,
+				"tapped":     true,
+			},
+		})
+	}
+
+	events = append(events, Event{
+		Type: "AbilityActivated",
+		Data: map[string]interface{}{
+			"player":           a.PlayerUID,
+			"instanceId":       source.InstanceID,
+			"cardId":           source.CardID,
+			"abilityIndex":     a.AbilityIndex,
+			"abilityName":      ability.Name,
+			"targetInstanceId": a.TargetID,
+			"x":                a.X,
+			"manaPool":         player.ManaPool,
+		},
+	})
+
+	if ability.Script != "" {
+		ctx := &ScriptContext{
+			Game:      g,
+			Card:      source,
+			Caster:    player,
+			CasterUID: a.PlayerUID,
+			Target:    targetCreature,
+			TargetUID: a.TargetPlayer,
+			X:         a.X,
+		}
+		scriptEvents := ExecuteScript(ability.Script, ctx)
+		events = append(events, scriptEvents...)
+
+		for uid, p := range g.Players {
+			events = append(events, g.handleDeaths(p, uid)...)
+		}
+
+		events = append(events, g.checkGameOver()...)
+	}
+
+	// Activating during the response window passes priority like an instant
+	if g.CombatPhase == "response_window" && g.Winner == "" {
+		g.PassedPlayers = make(map[string]bool)
+		events = append(events, g.passPriorityOn(a.PlayerUID))
+	}
+
+	return events
+}
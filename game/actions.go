@@ -26,4 +26,66 @@ type Action struct {
     Blockers   []BlockerDeclaration `json:"blockers"`   // For blocker assignments
     Message    string               `json:"message"`    // For chat messages
     Source     string               `json:"source"`     // For draw_card: "main" or "vault"
+
+    // For activate_ability: InstanceID is the permanent, TargetID the target creature
+    AbilityIndex int    `json:"abilityIndex"` // Index into the card's ActivatedAbilities
+    TargetPlayer string `json:"targetPlayer"` // UID for player-targeted abilities, and which opponent "opponent" scripts hit
+
+    // Optional explicit mana payment for play_card, play_instant, play_leader and abilities
+    // When omitted the server picks the payment that keeps the most options open
+    Payment *ManaCost `json:"payment,omitempty"`
+
+    // Auto-pay for play_card, play_instant and play_leader: tap untapped lands to cover the cost
+    AutoPay   bool `json:"autoPay,omitempty"`
+    BurnLands bool `json:"burnLands,omitempty"` // Also allow burning lands from hand when auto-paying
+
+    // Variable and alternative costs for play_card and play_instant
+    X          int   `json:"x,omitempty"`          // Value chosen for {X}
+    AltCost    int   `json:"altCost,omitempty"`    // 1-based index into the card's AltCosts (0 = normal cost)
+    DiscardIDs []int `json:"discardIds,omitempty"` // Cards discarded to pay an alternative cost
+
+    // Mana color chosen when tapping or burning a land with several options
+    Color string `json:"color,omitempty"`
+
+    // Deck contents for save_deck and update_deck (update_deck matches Deck.ID)
+    Deck *Deck `json:"deck,omitempty"`
+
+    // Deck code for import_deck, and the name to save the imported deck under
+    DeckCode string `json:"deckCode,omitempty"`
+    DeckName string `json:"deckName,omitempty"`
+
+    // Ranked flag for start_game, and season for get_leaderboard (empty = current season)
+    Ranked bool   `json:"ranked,omitempty"`
+    Season string `json:"season,omitempty"`
+
+    // Queue format for join_queue: "casual" (default) or "ranked"
+    // Limited format for create_draft: "draft" or "sealed"
+    Format string `json:"format,omitempty"`
+
+    // Lobby options for start_game, and the secret for join_specific_game
+    Private    bool   `json:"private,omitempty"`    // Hidden game joined with an invite code
+    Password   string `json:"password,omitempty"`   // Locked game joined with a password
+    InviteCode string `json:"inviteCode,omitempty"` // Joins a private game (gameId can be left empty)
+
+    // Best-of-N match for start_game (3 or 5); choose_first sends the player going first in TargetPlayer
+    // and sideboard sends the next game's deck in Deck (omitted = keep the current deck)
+    BestOf int `json:"bestOf,omitempty"`
+
+    // Seats for a free-for-all game in start_game (3 to MaxSeats; omitted = two players),
+    // and for create_draft (MinDraftSeats to MaxDraftSeats; bots fill the empty ones)
+    MaxPlayers int `json:"maxPlayers,omitempty"`
+
+    // Two-versus-two for start_game, with one life total per team if SharedLife is set
+    Teams      bool `json:"teams,omitempty"`
+    SharedLife bool `json:"sharedLife,omitempty"`
+
+    // Chat channel for chat: "team" for teammates only (omitted = everyone in the game)
+    Channel string `json:"channel,omitempty"`
+
+    // Rule set preset for start_game (omitted = standard rules)
+    RuleSet string `json:"ruleSet,omitempty"`
+
+    // Draft for join_draft, start_draft, draft_pick (the card is CardID) and build_draft_deck
+    // (Deck.Leader and Deck.MainDeck from your picks; the vault is added for you)
+    DraftID string `json:"draftId,omitempty"`
 }
@@ -0,0 +1,327 @@
+// autopay.go - Auto-pay: tapping and burning lands to cover a cost
+package game
+
+import "fmt"
+
+// manaSource is a group of interchangeable lands that can produce mana for auto-pay
+type manaSource struct {
+	Options  []ManaCost   // Mana each land in the group can produce (one per tap)
+	FromHand bool         // Burned from hand instead of tapped on the field
+	Field    []*FieldCard // Untapped lands on the field (when !FromHand)
+	Hand     []int        // Land card IDs in hand (when FromHand)
+}
+
+// count returns how many lands are in the group
+func (s *manaSource) count() int {
+	if s.FromHand {
+		return len(s.Hand)
+	}
+	return len(s.Field)
+}
+
+// landPayment is one land auto-pay uses and the mana it produces
+type landPayment struct {
+	Field  *FieldCard // Set when tapping a land on the field
+	CardID int        // Set when burning a land from hand
+	Mana   ManaCost
+}
+
+// manaPlan is the set of lands auto-pay decided to use
+type manaPlan struct {
+	Tap  []landPayment
+	Burn []landPayment
+	Adds ManaCost // Total mana the plan adds to the pool
+}
+
+// Add adds mana to the pool
+func (pool *ManaCost) Add(m ManaCost) {
+	pool.White += m.White
+	pool.Blue += m.Blue
+	pool.Black += m.Black
+	pool.Red += m.Red
+	pool.Green += m.Green
+	pool.Colorless += m.Colorless
+}
+
+// sameOptions compares two lands' mana choices
+func sameOptions(a, b []ManaCost) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameMana(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bestCase returns the most of each color any of the options produces
+// Used to estimate what an untapped land with choices keeps available
+func bestCase(options []ManaCost) ManaCost {
+	best := ManaCost{}
+	for _, o := range options {
+		best.White = max(best.White, o.White)
+		best.Blue = max(best.Blue, o.Blue)
+		best.Black = max(best.Black, o.Black)
+		best.Red = max(best.Red, o.Red)
+		best.Green = max(best.Green, o.Green)
+		best.Colorless = max(best.Colorless, o.Colorless)
+	}
+	return best
+}
+
+// manaSources groups the player's untapped lands, and optionally lands in hand, by the mana they provide
+func (p *Player) manaSources(burnLands bool) []*manaSource {
+	sources := []*manaSource{}
+	find := func(options []ManaCost, fromHand bool) *manaSource {
+		for _, s := range sources {
+			if sameOptions(s.Options, options) && s.FromHand == fromHand {
+				return s
+			}
+		}
+		s := &manaSource{Options: options, FromHand: fromHand}
+		sources = append(sources, s)
+		return s
+	}
+
+	for _, fc := range p.Field {
+		card := p.card(fc.CardID)
+		if card.CardType != "Land" || fc.IsTapped() {
+			continue
+		}
+		options := card.ManaOptions()
+		if len(options) == 0 {
+			continue
+		}
+		s := find(options, false)
+		s.Field = append(s.Field, fc)
+	}
+
+	if burnLands {
+		for _, cardID := range p.Hand {
+			card := p.card(cardID)
+			if card.CardType != "Land" {
+				continue
+			}
+			options := card.ManaOptions()
+			if len(options) == 0 {
+				continue
+			}
+			s := find(options, true)
+			s.Hand = append(s.Hand, cardID)
+		}
+	}
+
+	return sources
+}
+
+// planAutoPay picks which lands to tap (and burn), and which mana each produces, so the pool covers the cost
+// Prefers burning nothing, then keeping the most other cards castable and their colors
+// available, then leaving the least unused mana in the pool, then tapping as few lands
+// as possible. Returns false if the cost can't be covered.
+func (p *Player) planAutoPay(cost ManaCost, castingCardID int, burnLands bool) (manaPlan, bool) {
+	if p.ManaPool.CanAfford(cost) {
+		return manaPlan{}, true
+	}
+
+	sources := p.manaSources(burnLands)
+	options := p.futureCosts(castingCardID)
+
+	// counts[i][j] is how many lands of group i produce option j
+	counts := make([][]int, len(sources))
+	for i, s := range sources {
+		counts[i] = make([]int, len(s.Options))
+	}
+	var best [][]int
+	var bestScore []int
+
+	evaluate := func(pool ManaCost) {
+		if !pool.CanAfford(cost) {
+			return
+		}
+
+		// Score what's left to use afterwards: the pool after paying plus lands still untapped
+		left := pool
+		left.Subtract(p.choosePaymentFrom(pool, cost, castingCardID))
+		leftover := left.Total()
+		burned, tapped := 0, 0
+		for i, s := range sources {
+			used := 0
+			for _, n := range counts[i] {
+				used += n
+			}
+			if s.FromHand {
+				burned += used
+				continue
+			}
+			tapped += used
+			for n := used; n < s.count(); n++ {
+				left.Add(bestCase(s.Options))
+			}
+		}
+
+		future := scorePool(left, options)
+		score := []int{-burned, future[0], future[1], -leftover, -tapped}
+		if best == nil || betterScore(score, bestScore) {
+			best = make([][]int, len(counts))
+			for i := range counts {
+				best[i] = append([]int{}, counts[i]...)
+			}
+			bestScore = score
+		}
+	}
+
+	// Walk each group's options, splitting the group's lands between them
+	var search func(idx, opt, capacity int, adds ManaCost)
+	search = func(idx, opt, capacity int, adds ManaCost) {
+		pool := p.ManaPool
+		pool.Add(adds)
+
+		if idx == len(sources) {
+			evaluate(pool)
+			return
+		}
+		s := sources[idx]
+		if opt == len(s.Options) {
+			next := 0
+			if idx+1 < len(sources) {
+				next = sources[idx+1].count()
+			}
+			search(idx+1, 0, next, adds)
+			return
+		}
+
+		for n := 0; n <= capacity; n++ {
+			counts[idx][opt] = n
+			search(idx, opt+1, capacity-n, adds)
+			// Once the cost is covered, more lands only add leftovers
+			if pool.CanAfford(cost) {
+				break
+			}
+			adds.Add(s.Options[opt])
+			pool.Add(s.Options[opt])
+		}
+		counts[idx][opt] = 0
+	}
+	first := 0
+	if len(sources) > 0 {
+		first = sources[0].count()
+	}
+	search(0, 0, first, ManaCost{})
+
+	if best == nil {
+		return manaPlan{}, false
+	}
+
+	plan := manaPlan{}
+	for i, s := range sources {
+		land := 0
+		for opt, n := range best[i] {
+			for ; n > 0; n-- {
+				mana := s.Options[opt]
+				if s.FromHand {
+					plan.Burn = append(plan.Burn, landPayment{CardID: s.Hand[land], Mana: mana})
+				} else {
+					plan.Tap = append(plan.Tap, landPayment{Field: s.Field[land], Mana: mana})
+				}
+				plan.Adds.Add(mana)
+				land++
+			}
+		}
+	}
+	return plan, true
+}
+
+// applyManaPlan taps and burns the planned lands and returns the resulting events
+func (g *Game) applyManaPlan(playerUID string, plan manaPlan) []Event {
+	player := g.Players[playerUID]
+	events := []Event{}
+
+	for _, land := range plan.Tap {
+		fc, provided := land.Field, land.Mana
+		fc.SetTapped(true)
+		player.ManaPool.Add(provided)
+		events = append(events,
+			Event{
+				Type: "CardTapped",
+				Data: map[string]interface{}{
+					"player":     playerUID,
+					"instanceId": fc.InstanceID,
+					"tapped":     true,
+				},
+			},
+			Event{
+				Type: "ManaAdded",
+				Data: map[string]interface{}{
+					"player":   playerUID,
+					"added":    provided,
+					"manaPool": player.ManaPool,
+				},
+			},
+		)
+	}
+
+	for _, land := range plan.Burn {
+		cardID, provided := land.CardID, land.Mana
+		for i, c := range player.Hand {
+			if c == cardID {
+				player.Hand = append(player.Hand[:i], player.Hand[i+1:]...)
+				break
+			}
+		}
+		player.Discard = append(player.Discard, cardID)
+
+		player.ManaPool.Add(provided)
+		events = append(events,
+			Event{
+				Type: "CardBurned",
+				Data: map[string]interface{}{
+					"player": playerUID,
+					"cardId": cardID,
+				},
+			},
+			Event{
+				Type: "ManaAdded",
+				Data: map[string]interface{}{
+					"player":   playerUID,
+					"added":    provided,
+					"manaPool": player.ManaPool,
+				},
+			},
+		)
+	}
+
+	return events
+}
+
+// payFor pays a cost for a play action, auto-tapping lands first when the action asks for it
+// Returns the tap/burn events, which go before the play's own events.
+// Lands are only touched once the whole cost is known to be payable.
+func (g *Game) payFor(a Action, cost ManaCost, castingCardID int) ([]Event, error) {
+	player := g.Players[a.PlayerUID]
+
+	if !a.AutoPay {
+		return nil, player.payCost(cost, a.Payment, castingCardID)
+	}
+
+	plan, ok := player.planAutoPay(cost, castingCardID, a.BurnLands)
+	if !ok {
+		return nil, fmt.Errorf("Not enough mana from pool and untapped lands")
+	}
+
+	// Check an explicit payment before tapping anything
+	if a.Payment != nil {
+		pool := player.ManaPool
+		pool.Add(plan.Adds)
+		if err := pool.validateAnyPayment(cost, *a.Payment); err != nil {
+			return nil, fmt.Errorf("invalid payment: %v", err)
+		}
+	}
+
+	events := g.applyManaPlan(a.PlayerUID, plan)
+	if err := player.payCost(cost, a.Payment, castingCardID); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,251 @@
+package game
+
+import "testing"
+
+// testPool is a small card pool for tests that don't need the data files
+var testPool = &CardPool{Version: 1, Cards: map[int]Card{
+	1: {ID: 1, Name: "Plains", CardType: "Land", Provides: ManaCost{White: 1}},
+	2: {ID: 2, Name: "Island", CardType: "Land", Provides: ManaCost{Blue: 1}},
+	3: {ID: 3, Name: "Swamp", CardType: "Land", Provides: ManaCost{Black: 1}},
+	4: {ID: 4, Name: "Mountain", CardType: "Land", Provides: ManaCost{Red: 1}},
+	5: {ID: 5, Name: "Forest", CardType: "Land", Provides: ManaCost{Green: 1}},
+	6: {ID: 6, Name: "Wastes", CardType: "Land", Provides: ManaCost{Colorless: 1}},
+	7: {ID: 7, Name: "Azorius Gate", CardType: "Land", ManaChoices: []ManaCost{{White: 1}, {Blue: 1}}},
+
+	20: {ID: 20, Name: "Bear", CardType: "Creature", Cost: ManaCost{Green: 1, Colorless: 1}},
+	21: {ID: 21, Name: "Sprite", CardType: "Creature", Cost: ManaCost{Blue: 1}},
+	22: {ID: 22, Name: "Squire", CardType: "Creature", Cost: ManaCost{White: 1}},
+	23: {ID: 23, Name: "Goblin", CardType: "Creature", Cost: ManaCost{Red: 2}},
+	24: {ID: 24, Name: "Wisp", CardType: "Creature", Cost: ManaCost{Hybrid: []string{"W/U"}}},
+	25: {ID: 25, Name: "Fireball", CardType: "Spell", Cost: ManaCost{Red: 3, Colorless: 2},
+		AltCosts: []AltCost{{Name: "Overload", Cost: ManaCost{Red: 1}, Life: 3}}},
+	26: {ID: 26, Name: "Shade", CardType: "Creature", Cost: ManaCost{Black: 1}},
+	27: {ID: 27, Name: "Golem", CardType: "Creature", Cost: ManaCost{Colorless: 3}},
+	28: {ID: 28, Name: "Rot", CardType: "Creature", Cost: ManaCost{Colorless: 1, Hybrid: []string{"B/G"}}},
+}}
+
+// testPlayer returns a player using testPool with the given untapped lands on the field and cards in hand
+func testPlayer(field, hand []int) *Player {
+	p := &Player{UID: "a", Hand: hand, pool: testPool}
+	for i, id := range field {
+		p.Field = append(p.Field, &FieldCard{InstanceID: i + 1, CardID: id, Owner: "a", pool: testPool})
+	}
+	return p
+}
+
+func TestPlanAutoPay(t *testing.T) {
+	tests := []struct {
+		name      string
+		pool      ManaCost
+		field     []int
+		hand      []int
+		cost      ManaCost
+		burnLands bool
+		ok        bool
+		tapped    int
+		burned    int
+		adds      ManaCost
+	}{
+		{
+			name: "pool already covers the cost",
+			pool: ManaCost{Green: 2},
+			cost: ManaCost{Green: 1, Colorless: 1},
+			ok:   true,
+		},
+		{
+			name:   "taps the lands the hand doesn't need",
+			field:  []int{5, 5, 2},
+			hand:   []int{21},
+			cost:   ManaCost{Green: 1, Colorless: 1},
+			ok:     true,
+			tapped: 2,
+			adds:   ManaCost{Green: 2},
+		},
+		{
+			name:   "hybrid paid with the color the hand doesn't need",
+			field:  []int{1, 2},
+			hand:   []int{21},
+			cost:   ManaCost{Hybrid: []string{"W/U"}},
+			ok:     true,
+			tapped: 1,
+			adds:   ManaCost{White: 1},
+		},
+		{
+			name:   "hybrid plus colorless",
+			field:  []int{3, 5, 5},
+			hand:   []int{26},
+			cost:   ManaCost{Colorless: 1, Hybrid: []string{"B/G"}},
+			ok:     true,
+			tapped: 2,
+			adds:   ManaCost{Green: 2},
+		},
+		{
+			name:   "dual land makes the missing color",
+			field:  []int{7, 1},
+			cost:   ManaCost{White: 1, Blue: 1},
+			ok:     true,
+			tapped: 2,
+			adds:   ManaCost{White: 1, Blue: 1},
+		},
+		{
+			name:      "burns a land from hand only when the field can't cover it",
+			field:     []int{5},
+			hand:      []int{4, 5},
+			cost:      ManaCost{Red: 1, Green: 1},
+			burnLands: true,
+			ok:        true,
+			tapped:    1,
+			burned:    1,
+			adds:      ManaCost{Red: 1, Green: 1},
+		},
+		{
+			name:      "doesn't burn when the field covers it",
+			field:     []int{4, 5},
+			hand:      []int{4},
+			cost:      ManaCost{Red: 1, Green: 1},
+			burnLands: true,
+			ok:        true,
+			tapped:    2,
+			adds:      ManaCost{Red: 1, Green: 1},
+		},
+		{
+			name:  "lands in hand aren't used without burning",
+			field: []int{5},
+			hand:  []int{4},
+			cost:  ManaCost{Red: 1, Green: 1},
+		},
+		{
+			name:  "missing color",
+			field: []int{2, 2},
+			cost:  ManaCost{Green: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(tt.field, tt.hand)
+			p.ManaPool = tt.pool
+			plan, ok := p.planAutoPay(tt.cost, 0, tt.burnLands)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if len(plan.Tap) != tt.tapped || len(plan.Burn) != tt.burned {
+				t.Errorf("tapped %d and burned %d, want %d and %d", len(plan.Tap), len(plan.Burn), tt.tapped, tt.burned)
+			}
+			if !sameMana(plan.Adds, tt.adds) {
+				t.Errorf("adds %+v, want %+v", plan.Adds, tt.adds)
+			}
+		})
+	}
+}
+
+func TestPlanAutoPayAltCost(t *testing.T) {
+	p := testPlayer([]int{4, 4}, []int{25})
+	fireball := testPool.Card(25)
+
+	cost, alt, err := resolveCost(fireball, &Action{})
+	if err != nil || alt != nil {
+		t.Fatalf("normal cost: alt %v, err %v", alt, err)
+	}
+	if _, ok := p.planAutoPay(cost, 25, false); ok {
+		t.Errorf("two Mountains paid %+v", cost)
+	}
+
+	cost, alt, err = resolveCost(fireball, &Action{AltCost: 1})
+	if err != nil || alt == nil || alt.Life != 3 {
+		t.Fatalf("alt cost: alt %v, err %v", alt, err)
+	}
+	plan, ok := p.planAutoPay(cost, 25, false)
+	if !ok || len(plan.Tap) != 1 || !sameMana(plan.Adds, ManaCost{Red: 1}) {
+		t.Errorf("alt cost %+v: ok %v, plan %+v", cost, ok, plan)
+	}
+
+	if _, _, err := resolveCost(fireball, &Action{AltCost: 2}); err == nil {
+		t.Error("resolved an alternative cost the card doesn't have")
+	}
+}
+
+func TestChoosePaymentFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		pool ManaCost
+		hand []int
+		cost ManaCost
+		want ManaCost
+	}{
+		{
+			name: "hybrid keeps the color the hand needs",
+			pool: ManaCost{White: 1, Blue: 1},
+			hand: []int{21},
+			cost: ManaCost{Hybrid: []string{"W/U"}},
+			want: ManaCost{White: 1},
+		},
+		{
+			name: "hybrid keeps the other color for another hand",
+			pool: ManaCost{White: 1, Blue: 1},
+			hand: []int{22},
+			cost: ManaCost{Hybrid: []string{"W/U"}},
+			want: ManaCost{Blue: 1},
+		},
+		{
+			name: "hybrid and colorless",
+			pool: ManaCost{Black: 1, Green: 2},
+			hand: []int{26},
+			cost: ManaCost{Colorless: 1, Hybrid: []string{"B/G"}},
+			want: ManaCost{Green: 2},
+		},
+		{
+			name: "colorless mana pays colorless first",
+			pool: ManaCost{Green: 2, Colorless: 1},
+			hand: []int{20},
+			cost: ManaCost{Green: 1, Colorless: 1},
+			want: ManaCost{Green: 1, Colorless: 1},
+		},
+		{
+			name: "colorless cost keeps what the hand can cast",
+			pool: ManaCost{Red: 2, Blue: 1},
+			hand: []int{23},
+			cost: ManaCost{Colorless: 1},
+			want: ManaCost{Blue: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer(nil, tt.hand)
+			if got := p.choosePaymentFrom(tt.pool, tt.cost, 0); !sameMana(got, tt.want) {
+				t.Errorf("paid %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayForExplicitHybridPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment ManaCost
+		ok      bool
+	}{
+		{name: "payment the tapped land covers", payment: ManaCost{White: 1}, ok: true},
+		{name: "payment in the other hybrid color", payment: ManaCost{Blue: 1}},
+		{name: "payment in a color the cost doesn't take", payment: ManaCost{Red: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlayer([]int{1}, nil)
+			// The pool's Red can't pay the hybrid symbol, so auto-pay taps the Plains
+			p.ManaPool = ManaCost{Red: 1}
+			g := &Game{Players: map[string]*Player{"a": p}, Pool: testPool}
+			payment := tt.payment
+			a := Action{PlayerUID: "a", AutoPay: true, Payment: &payment}
+			_, err := g.payFor(a, ManaCost{Hybrid: []string{"W/U"}}, 0)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+			if !tt.ok && p.Field[0].IsTapped() {
+				t.Error("tapped a land for a payment that was refused")
+			}
+		})
+	}
+}
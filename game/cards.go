@@ -12,21 +12,34 @@ type ManaCost struct {
 	Red       int `json:"Red,omitempty"`
 	Green     int `json:"Green,omitempty"`
 	Colorless int `json:"Colorless,omitempty"`
+
+	X      int      `json:"X,omitempty"`      // Number of {X} symbols; the caster picks X when paying
+	Hybrid []string `json:"Hybrid,omitempty"` // Hybrid symbols like "W/U", payable with either color
 }
 
-// Total returns the total mana (colored + colorless)
+// Total returns the total mana (colored + colorless + hybrid, X counted as 0)
 func (m ManaCost) Total() int {
-	return m.White + m.Blue + m.Black + m.Red + m.Green + m.Colorless
+	return m.White + m.Blue + m.Black + m.Red + m.Green + m.Colorless + len(m.Hybrid)
 }
 
 // ColoredTotal returns just the colored mana total (excluding colorless)
 func (m ManaCost) ColoredTotal() int {
-	return m.White + m.Blue + m.Black + m.Red + m.Green
+	return m.White + m.Blue + m.Black + m.Red + m.Green + len(m.Hybrid)
 }
 
 // CanAfford checks if available mana can pay the cost
-// Colorless cost can be paid with any color
+// Colorless cost can be paid with any color; hybrid symbols with either of their colors.
+// X is treated as 0 - resolve it with WithX first.
 func (available ManaCost) CanAfford(cost ManaCost) bool {
+	if len(cost.Hybrid) > 0 {
+		for _, alt := range cost.Alternatives() {
+			if available.CanAfford(alt) {
+				return true
+			}
+		}
+		return false
+	}
+
 	// First check colored requirements
 	if available.White < cost.White ||
 		available.Blue < cost.Blue ||
@@ -51,6 +64,16 @@ func (available ManaCost) CanAfford(cost ManaCost) bool {
 // Spend deducts the cost from the mana pool (modifies in place via pointer)
 // Returns the updated pool after spending
 func (pool *ManaCost) Spend(cost ManaCost) {
+	// Pay hybrid symbols with the first color choice the pool can afford
+	if len(cost.Hybrid) > 0 {
+		for _, alt := range cost.Alternatives() {
+			if pool.CanAfford(alt) {
+				cost = alt
+				break
+			}
+		}
+	}
+
 	// Deduct colored costs first
 	pool.White -= cost.White
 	pool.Blue -= cost.Blue
@@ -92,18 +115,44 @@ func (pool *ManaCost) Clear() {
 	pool.Colorless = 0
 }
 
+// ActivatedAbility is an ability a permanent can use by paying its cost
+// e.g. "{T}: deal 1 damage to target creature" or "{2}{G}: +1/+1 until end of turn"
+type ActivatedAbility struct {
+	Name   string   `json:"Name"`
+	Text   string   `json:"Text"`
+	Cost   ManaCost `json:"Cost"`
+	Tap    bool     `json:"Tap"` // Whether the permanent must tap to activate
+	Script string   `json:"Script"`
+}
+
 type Card struct {
-	ID                 int      `json:"ID"`
-	Name               string   `json:"Name"`
-	Cost               ManaCost `json:"Cost"`
-	Provides           ManaCost `json:"Provides"` // What mana this land produces
-	Attack             int      `json:"Attack"`
-	Defense            int      `json:"Defense"`
-	CardType           string   `json:"CardType"`
-	CardText           string   `json:"CardText"`
-	Abilities          []string `json:"Abilities"`
-	ValidAttackTargets string   `json:"ValidAttackTargets"`
-	CustomScript       string   `json:"CustomScript"`
+	ID                 int                `json:"ID"`
+	Name               string             `json:"Name"`
+	Cost               ManaCost           `json:"Cost"`
+	Provides           ManaCost           `json:"Provides"`               // What mana this land produces
+	ManaChoices        []ManaCost         `json:"ManaChoices,omitempty"`  // Lands: produce one of these when tapped (overrides Provides)
+	EntersTapped       bool               `json:"EntersTapped,omitempty"` // Lands: enters the field tapped
+	Attack             int                `json:"Attack"`
+	Defense            int                `json:"Defense"`
+	CardType           string             `json:"CardType"`
+	CardText           string             `json:"CardText"`
+	Abilities          []string           `json:"Abilities"`
+	ValidAttackTargets string             `json:"ValidAttackTargets"`
+	CustomScript       string             `json:"CustomScript"`
+	ActivatedAbilities []ActivatedAbility `json:"ActivatedAbilities,omitempty"`
+	HeroPower          *ActivatedAbility  `json:"HeroPower,omitempty"` // Leaders only: usable from the leader zone
+	AltCosts           []AltCost          `json:"AltCosts,omitempty"`  // Other ways to pay for the card
+	Rarity             string             `json:"Rarity,omitempty"`    // Pack rarity for limited formats (see PackRarity)
+}
+
+// AltCost is an alternative way to pay for a card instead of its mana cost
+// e.g. "pay 4 life" or "{R} and discard a card"
+type AltCost struct {
+	Name    string   `json:"Name"`
+	Text    string   `json:"Text"`
+	Cost    ManaCost `json:"Cost"`    // Mana still required (may be empty)
+	Life    int      `json:"Life"`    // Life the caster pays
+	Discard int      `json:"Discard"` // Cards the caster discards from hand
 }
 
 // HasAbility checks if the card has a specific ability
@@ -116,55 +165,39 @@ func (c Card) HasAbility(ability string) bool {
 	return false
 }
 
-// GetProvidedMana returns the mana this card provides (for lands)
-func (c Card) GetProvidedMana() ManaCost {
-	// If Provides is explicitly set, use it
-	if c.Provides.Total() > 0 {
-		return c.Provides
-	}
-	// Otherwise, derive from card name for basic lands
-	if c.CardType == "Land" {
-		switch {
-		case contains(c.Name, "White"):
-			return ManaCost{White: 1}
-		case contains(c.Name, "Blue"):
-			return ManaCost{Blue: 1}
-		case contains(c.Name, "Black"):
-			return ManaCost{Black: 1}
-		case contains(c.Name, "Red"):
-			return ManaCost{Red: 1}
-		case contains(c.Name, "Green"):
-			return ManaCost{Green: 1}
-		case contains(c.Name, "Omni"):
-			return ManaCost{Green: 1, Red: 1, Black: 1, Blue: 1, White: 1}
-		}
+// ManaOptions returns the mana a land can produce, one entry per choice
+// A land with ManaChoices picks one of them each time it's tapped; otherwise Provides is its only option.
+func (c Card) ManaOptions() []ManaCost {
+	if len(c.ManaChoices) > 0 {
+		return c.ManaChoices
 	}
-	return ManaCost{}
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
-}
-
-func containsHelper(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
+	if c.Provides.Total() > 0 {
+		return []ManaCost{c.Provides}
 	}
-	return false
+	return nil
 }
 
+// CardDB holds the cards loaded at startup (version 1 of the pool)
+// Lookups during play go through the game's CardPool so reloads don't affect running games.
 var CardDB = map[int]Card{}
 
-func LoadCards(path string) error {
+// ReadCards parses a cards file without loading it into CardDB
+func ReadCards(path string) ([]Card, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var cards []Card
 	if err := json.Unmarshal(data, &cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+func LoadCards(path string) error {
+	cards, err := ReadCards(path)
+	if err != nil {
 		return err
 	}
 
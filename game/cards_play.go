@@ -17,24 +17,52 @@ func (g *Game) playCard(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card not in hand"}}}
 	}
 
-	card := CardDB[a.CardID]
+	card := g.Card(a.CardID)
+
+	cost, alt, err := resolveCost(card, &a)
+	if err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": err.Error()}}}
+	}
+	if err := player.checkAltCost(alt, a.CardID, a.DiscardIDs); err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": err.Error()}}}
+	}
+	// Check the land limit before anything is paid
+	if card.CardType == "Land" && player.LandsPlayedThisTurn >= player.LandsPerTurn {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message":      "Already played max lands this turn",
+			"landsPlayed":  player.LandsPlayedThisTurn,
+			"landsPerTurn": player.LandsPerTurn,
+		}}}
+	}
+	if alt != nil && alt.Discard > 0 {
+		// Don't let auto-pay burn the cards chosen for the discard
+		a.BurnLands = false
+	}
 
 	// Check mana cost (lands are free)
-	if card.CardType != "Land" && card.Cost.Total() > 0 {
-		if !player.ManaPool.CanAfford(card.Cost) {
+	events := []Event{}
+	if card.CardType != "Land" && cost.Total() > 0 {
+		payEvents, err := g.payFor(a, cost, a.CardID)
+		if err != nil {
 			return []Event{{Type: "Error", Data: map[string]interface{}{
-				"message":   "Not enough mana in pool",
-				"required":  card.Cost,
+				"message":   err.Error(),
+				"required":  cost,
 				"available": player.ManaPool,
 			}}}
 		}
-		player.ManaPool.Spend(card.Cost)
+		events = append(events, payEvents...)
+	}
+	if alt != nil {
+		events = append(events, g.payAltCost(a.PlayerUID, alt, a.CardID, a.DiscardIDs))
 	}
 
-	// Remove from hand
-	player.Hand = append(player.Hand[:cardIdx], player.Hand[cardIdx+1:]...)
-
-	events := []Event{}
+	// Remove from hand (auto-pay may have burned other cards, so find it again)
+	for i, c := range player.Hand {
+		if c == a.CardID {
+			player.Hand = append(player.Hand[:i], player.Hand[i+1:]...)
+			break
+		}
+	}
 
 	switch card.CardType {
 	case "Creature":
@@ -59,6 +87,8 @@ func (g *Game) playCard(a Action) []Event {
 				Card:      fieldCard,
 				Caster:    player,
 				CasterUID: a.PlayerUID,
+				TargetUID: a.TargetPlayer,
+				X:         a.X,
 			}
 			scriptEvents := ExecuteScript(card.CustomScript, ctx)
 			events = append(events, scriptEvents...)
@@ -69,16 +99,10 @@ func (g *Game) playCard(a Action) []Event {
 		}
 
 	case "Land":
-		if player.LandsPlayedThisTurn >= player.LandsPerTurn {
-			player.Hand = append(player.Hand, a.CardID)
-			return []Event{{Type: "Error", Data: map[string]interface{}{
-				"message":      "Already played max lands this turn",
-				"landsPlayed":  player.LandsPlayedThisTurn,
-				"landsPerTurn": player.LandsPerTurn,
-			}}}
-		}
-
 		fieldCard := g.NewFieldCard(a.CardID, a.PlayerUID, a.PlayerUID)
+		if card.EntersTapped {
+			fieldCard.SetTapped(true)
+		}
 		player.Field = append(player.Field, fieldCard)
 		player.LandsPlayedThisTurn++
 
@@ -104,6 +128,7 @@ func (g *Game) playCard(a Action) []Event {
 				"player":   a.PlayerUID,
 				"cardId":   a.CardID,
 				"manaPool": player.ManaPool,
+				"x":        a.X,
 			},
 		})
 
@@ -114,6 +139,8 @@ func (g *Game) playCard(a Action) []Event {
 				Card:      nil,
 				Caster:    player,
 				CasterUID: a.PlayerUID,
+				TargetUID: a.TargetPlayer,
+				X:         a.X,
 			}
 			scriptEvents := ExecuteScript(card.CustomScript, ctx)
 			events = append(events, scriptEvents...)
@@ -122,21 +149,7 @@ func (g *Game) playCard(a Action) []Event {
 				events = append(events, g.handleDeaths(p, uid)...)
 			}
 
-			// Check for game over
-			for uid, p := range g.Players {
-				if p.Life <= 0 && g.Winner == "" {
-					for otherUID := range g.Players {
-						if otherUID != uid {
-							g.Winner = otherUID
-							events = append(events, Event{
-								Type: "GameOver",
-								Data: map[string]interface{}{"winner": otherUID},
-							})
-							break
-						}
-					}
-				}
-			}
+			events = append(events, g.checkGameOver()...)
 		}
 	}
 
@@ -151,37 +164,40 @@ func (g *Game) playLeader(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "No leader to play or already played"}}}
 	}
 
-	card := CardDB[player.Leader]
+	card := g.Card(player.Leader)
+	cost := player.LeaderCost()
 
-	if card.Cost.Total() > 0 {
-		if !player.ManaPool.CanAfford(card.Cost) {
+	events := []Event{}
+	if cost.Total() > 0 {
+		payEvents, err := g.payFor(a, cost, 0)
+		if err != nil {
 			return []Event{{Type: "Error", Data: map[string]interface{}{
-				"message":   "Not enough mana in pool",
-				"required":  card.Cost,
+				"message":   err.Error(),
+				"required":  cost,
 				"available": player.ManaPool,
 			}}}
 		}
-		player.ManaPool.Spend(card.Cost)
+		events = append(events, payEvents...)
 	}
 
 	fieldCard := g.NewFieldCard(player.Leader, a.PlayerUID, a.PlayerUID)
+	fieldCard.IsLeader = true
 	player.Field = append(player.Field, fieldCard)
 
 	leaderID := player.Leader
 	player.Leader = 0
 
-	events := []Event{
-		{
-			Type: "LeaderPlayed",
-			Data: map[string]interface{}{
-				"player":     a.PlayerUID,
-				"cardId":     leaderID,
-				"instanceId": fieldCard.InstanceID,
-				"fieldCard":  fieldCard,
-				"manaPool":   player.ManaPool,
-			},
+	events = append(events, Event{
+		Type: "LeaderPlayed",
+		Data: map[string]interface{}{
+			"player":     a.PlayerUID,
+			"cardId":     leaderID,
+			"instanceId": fieldCard.InstanceID,
+			"fieldCard":  fieldCard,
+			"manaPool":   player.ManaPool,
+			"recastTax":  player.LeaderTax(),
 		},
-	}
+	})
 
 	if card.CustomScript != "" {
 		ctx := &ScriptContext{
@@ -189,6 +205,7 @@ func (g *Game) playLeader(a Action) []Event {
 			Card:      fieldCard,
 			Caster:    player,
 			CasterUID: a.PlayerUID,
+			TargetUID: a.TargetPlayer,
 		}
 		scriptEvents := ExecuteScript(card.CustomScript, ctx)
 		events = append(events, scriptEvents...)
@@ -221,6 +238,15 @@ func (g *Game) tapCard(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card is already tapped"}}}
 	}
 
+	card := g.Card(targetCard.CardID)
+	provided, err := chooseManaOption(card, a.Color)
+	if err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message": err.Error(),
+			"options": card.ManaOptions(),
+		}}}
+	}
+
 	targetCard.SetTapped(true)
 
 	events := []Event{
@@ -235,15 +261,8 @@ func (g *Game) tapCard(a Action) []Event {
 	}
 
 	// Add mana if it's a land
-	card := CardDB[targetCard.CardID]
 	if card.CardType == "Land" {
-		provided := card.GetProvidedMana()
-		player.ManaPool.White += provided.White
-		player.ManaPool.Blue += provided.Blue
-		player.ManaPool.Black += provided.Black
-		player.ManaPool.Red += provided.Red
-		player.ManaPool.Green += provided.Green
-		player.ManaPool.Colorless += provided.Colorless
+		player.ManaPool.Add(provided)
 
 		events = append(events, Event{
 			Type: "ManaAdded",
@@ -273,22 +292,24 @@ func (g *Game) burnCard(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card not in hand"}}}
 	}
 
-	card := CardDB[a.CardID]
+	card := g.Card(a.CardID)
 
 	if card.CardType != "Land" {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Only lands can be burned for mana"}}}
 	}
 
+	provided, err := chooseManaOption(card, a.Color)
+	if err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message": err.Error(),
+			"options": card.ManaOptions(),
+		}}}
+	}
+
 	player.Hand = append(player.Hand[:cardIdx], player.Hand[cardIdx+1:]...)
 	player.Discard = append(player.Discard, a.CardID)
 
-	provided := card.GetProvidedMana()
-	player.ManaPool.White += provided.White
-	player.ManaPool.Blue += provided.Blue
-	player.ManaPool.Black += provided.Black
-	player.ManaPool.Red += provided.Red
-	player.ManaPool.Green += provided.Green
-	player.ManaPool.Colorless += provided.Colorless
+	player.ManaPool.Add(provided)
 
 	return []Event{
 		{
@@ -1,6 +1,8 @@
 // combat.go - Combat system: attacks, response window, damage resolution
 package game
 
+import "slices"
+
 // getUntappedTaunts returns all untapped creatures with Taunt on a player's field
 func getUntappedTaunts(player *Player) []*FieldCard {
 	taunts := []*FieldCard{}
@@ -8,8 +10,7 @@ func getUntappedTaunts(player *Player) []*FieldCard {
 		if fc.IsTapped() {
 			continue
 		}
-		card := CardDB[fc.CardID]
-		if card.HasAbility("Taunt") {
+		if fc.HasAbility("Taunt") {
 			taunts = append(taunts, fc)
 		}
 	}
@@ -28,23 +29,16 @@ func (g *Game) declareAttacks(a Action) []Event {
 
 	player := g.Players[a.PlayerUID]
 
-	// Find opponent
-	var opponentUID string
-	for uid := range g.Players {
-		if uid != a.PlayerUID {
-			opponentUID = uid
-			break
+	// Taunt creatures protect the player they belong to, and in team games their whole team
+	tauntIDs := make(map[string]map[int]bool)
+	for _, uid := range g.opponentsOf(a.PlayerUID) {
+		tauntIDs[uid] = make(map[int]bool)
+		for _, mate := range g.teamOf(uid) {
+			for _, fc := range getUntappedTaunts(g.Players[mate]) {
+				tauntIDs[uid][fc.InstanceID] = true
+			}
 		}
 	}
-	opponent := g.Players[opponentUID]
-
-	// Check for Taunt creatures
-	tauntCreatures := getUntappedTaunts(opponent)
-	hasTaunts := len(tauntCreatures) > 0
-	tauntIDs := make(map[int]bool)
-	for _, fc := range tauntCreatures {
-		tauntIDs[fc.InstanceID] = true
-	}
 
 	// Validate attacks and build pending attacks
 	pendingAttacks := []PendingAttack{}
@@ -69,36 +63,35 @@ func (g *Game) declareAttacks(a Action) []Event {
 		if attacker.IsSummoned() {
 			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Attacker has summoning sickness", "instanceId": atk.AttackerInstanceID}}}
 		}
+		if attacker.HasStatus(StatusStunned) || attacker.HasStatus(StatusFrozen) {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Attacker is stunned or frozen", "instanceId": atk.AttackerInstanceID}}}
+		}
 
-		card := CardDB[attacker.CardID]
+		card := g.Card(attacker.CardID)
 		validTargets := card.ValidAttackTargets
 		if validTargets == "" {
 			validTargets = "Any"
 		}
 
 		// Validate target
+		// Creature attacks record the creature's owner as the defending player
 		if atk.TargetType == "creature" {
 			if validTargets == "Player" {
 				return []Event{{Type: "Error", Data: map[string]interface{}{"message": "This creature can only attack players", "instanceId": atk.AttackerInstanceID}}}
 			}
-			var target *FieldCard
-			for _, fc := range opponent.Field {
-				if fc.InstanceID == atk.TargetInstanceID {
-					target = fc
-					break
-				}
-			}
-			if target == nil {
+			target, owner := g.findFieldCard(atk.TargetInstanceID)
+			if target == nil || !g.isOpponent(a.PlayerUID, owner) {
 				return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Target creature not found", "targetInstanceId": atk.TargetInstanceID}}}
 			}
+			atk.TargetPlayerUID = owner
 		} else if atk.TargetType == "player" {
 			if validTargets == "Creatures" {
 				return []Event{{Type: "Error", Data: map[string]interface{}{"message": "This creature can only attack creatures", "instanceId": atk.AttackerInstanceID}}}
 			}
-			if atk.TargetPlayerUID != opponentUID {
-				return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Can only attack opponent"}}}
+			if !g.isOpponent(a.PlayerUID, atk.TargetPlayerUID) {
+				return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Can only attack an opponent"}}}
 			}
-			if hasTaunts {
+			if len(tauntIDs[atk.TargetPlayerUID]) > 0 {
 				return []Event{{Type: "Error", Data: map[string]interface{}{
 					"message":    "Cannot attack player while opponent has Taunt creatures",
 					"instanceId": atk.AttackerInstanceID,
@@ -109,7 +102,8 @@ func (g *Game) declareAttacks(a Action) []Event {
 		}
 
 		// Taunt check for creature targets
-		if hasTaunts && atk.TargetType == "creature" && !tauntIDs[atk.TargetInstanceID] {
+		taunts := tauntIDs[atk.TargetPlayerUID]
+		if len(taunts) > 0 && atk.TargetType == "creature" && !taunts[atk.TargetInstanceID] {
 			return []Event{{Type: "Error", Data: map[string]interface{}{
 				"message":          "Must attack a creature with Taunt",
 				"instanceId":       atk.AttackerInstanceID,
@@ -138,10 +132,10 @@ func (g *Game) declareAttacks(a Action) []Event {
 	// Build attacks with abilities for client
 	attacksWithAbilities := []map[string]interface{}{}
 	for _, pa := range pendingAttacks {
-		var attackerCard Card
+		var attackerAbilities []string
 		for _, fc := range player.Field {
 			if fc.InstanceID == pa.AttackerInstanceID {
-				attackerCard = CardDB[fc.CardID]
+				attackerAbilities = fc.ActiveAbilities()
 				break
 			}
 		}
@@ -150,7 +144,7 @@ func (g *Game) declareAttacks(a Action) []Event {
 			"targetType":         pa.TargetType,
 			"targetInstanceId":   pa.TargetInstanceID,
 			"targetPlayerUid":    pa.TargetPlayerUID,
-			"attackerAbilities":  attackerCard.Abilities,
+			"attackerAbilities":  attackerAbilities,
 		})
 	}
 
@@ -176,20 +170,27 @@ func (g *Game) declareAttacks(a Action) []Event {
 		},
 	})
 
-	// Enter response window
+	// Enter response window: priority goes around the table, starting after the attacker
 	g.CombatPhase = "response_window"
-	g.PriorityPlayer = opponentUID
+	g.PriorityPlayer = g.nextAlive(a.PlayerUID)
 	g.PassedPlayers = make(map[string]bool)
 
-	defenderInstants := g.getInstantsInHand(opponentUID)
+	defenders := []string{}
+	for _, pa := range pendingAttacks {
+		if !slices.Contains(defenders, pa.TargetPlayerUID) {
+			defenders = append(defenders, pa.TargetPlayerUID)
+		}
+	}
+	defenderInstants := g.getInstantsInHand(g.PriorityPlayer)
 	attackerInstants := g.getInstantsInHand(a.PlayerUID)
 
 	events = append(events, Event{
 		Type: "ResponseWindow",
 		Data: map[string]interface{}{
 			"attacker":         a.PlayerUID,
-			"defender":         opponentUID,
-			"priorityPlayer":   opponentUID,
+			"defender":         defenders[0],
+			"defenders":        defenders,
+			"priorityPlayer":   g.PriorityPlayer,
 			"attacks":          attacksWithAbilities,
 			"defenderInstants": defenderInstants,
 			"attackerInstants": attackerInstants,
@@ -204,9 +205,10 @@ func (g *Game) getInstantsInHand(playerUID string) []map[string]interface{} {
 	player := g.Players[playerUID]
 	instants := []map[string]interface{}{}
 	for _, cardID := range player.Hand {
-		card := CardDB[cardID]
+		card := g.Card(cardID)
 		if card.CardType == "Instant" {
-			canAfford := player.ManaPool.CanAfford(card.Cost)
+			// Untapped lands and alternative costs count too
+			canAfford := player.canCast(card, cardID)
 			instants = append(instants, map[string]interface{}{
 				"cardId":    cardID,
 				"name":      card.Name,
@@ -238,20 +240,12 @@ func (g *Game) playInstant(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Card not in hand"}}}
 	}
 
-	card := CardDB[a.CardID]
+	card := g.Card(a.CardID)
 
 	if card.CardType != "Instant" {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Only instants can be played during combat"}}}
 	}
 
-	if !player.ManaPool.CanAfford(card.Cost) {
-		return []Event{{Type: "Error", Data: map[string]interface{}{
-			"message":   "Not enough mana",
-			"required":  card.Cost,
-			"available": player.ManaPool,
-		}}}
-	}
-
 	// Find target creature
 	var targetCreature *FieldCard
 	if a.InstanceID != 0 {
@@ -268,22 +262,48 @@ func (g *Game) playInstant(a Action) []Event {
 		}
 	}
 
+	cost, alt, err := resolveCost(card, &a)
+	if err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": err.Error()}}}
+	}
+	if err := player.checkAltCost(alt, a.CardID, a.DiscardIDs); err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": err.Error()}}}
+	}
+	if alt != nil && alt.Discard > 0 {
+		// Don't let auto-pay burn the cards chosen for the discard
+		a.BurnLands = false
+	}
+
 	// Spend mana and remove from hand
-	player.ManaPool.Spend(card.Cost)
-	player.Hand = append(player.Hand[:cardIdx], player.Hand[cardIdx+1:]...)
+	payEvents, err := g.payFor(a, cost, a.CardID)
+	if err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message":   err.Error(),
+			"required":  cost,
+			"available": player.ManaPool,
+		}}}
+	}
+	if alt != nil {
+		payEvents = append(payEvents, g.payAltCost(a.PlayerUID, alt, a.CardID, a.DiscardIDs))
+	}
+	for i, c := range player.Hand {
+		if c == a.CardID {
+			player.Hand = append(player.Hand[:i], player.Hand[i+1:]...)
+			break
+		}
+	}
 	player.Discard = append(player.Discard, a.CardID)
 
-	events := []Event{
-		{
-			Type: "InstantPlayed",
-			Data: map[string]interface{}{
-				"player":           a.PlayerUID,
-				"cardId":           a.CardID,
-				"targetInstanceId": a.InstanceID,
-				"manaPool":         player.ManaPool,
-			},
+	events := append(payEvents, Event{
+		Type: "InstantPlayed",
+		Data: map[string]interface{}{
+			"player":           a.PlayerUID,
+			"cardId":           a.CardID,
+			"targetInstanceId": a.InstanceID,
+			"manaPool":         player.ManaPool,
+			"x":                a.X,
 		},
-	}
+	})
 
 	// Execute script
 	if card.CustomScript != "" {
@@ -292,7 +312,9 @@ func (g *Game) playInstant(a Action) []Event {
 			Card:      nil,
 			Caster:    player,
 			CasterUID: a.PlayerUID,
+			TargetUID: a.TargetPlayer,
 			Target:    targetCreature,
+			X:         a.X,
 		}
 		scriptEvents := ExecuteScript(card.CustomScript, ctx)
 		events = append(events, scriptEvents...)
@@ -302,21 +324,9 @@ func (g *Game) playInstant(a Action) []Event {
 		}
 	}
 
-	// Reset passes and switch priority
+	// Reset passes and move priority on around the table
 	g.PassedPlayers = make(map[string]bool)
-	for uid := range g.Players {
-		if uid != a.PlayerUID {
-			g.PriorityPlayer = uid
-			break
-		}
-	}
-
-	events = append(events, Event{
-		Type: "PriorityChanged",
-		Data: map[string]interface{}{
-			"priorityPlayer": g.PriorityPlayer,
-		},
-	})
+	events = append(events, g.passPriorityOn(a.PlayerUID))
 
 	return events
 }
@@ -329,36 +339,17 @@ func (g *Game) passPriority(a Action) []Event {
 
 	g.PassedPlayers[a.PlayerUID] = true
 
-	// Check if both passed
-	allPassed := true
-	for uid := range g.Players {
-		if !g.PassedPlayers[uid] {
-			allPassed = false
-			break
-		}
-	}
-
-	if allPassed {
+	// Combat resolves once everyone still in the game passed in a row
+	if g.allPassed() {
 		return g.resolveCombat()
 	}
 
-	// Switch priority
-	for uid := range g.Players {
-		if uid != a.PlayerUID {
-			g.PriorityPlayer = uid
-			break
-		}
-	}
-
 	return []Event{
 		{
 			Type: "PlayerPassed",
 			Data: map[string]interface{}{"player": a.PlayerUID},
 		},
-		{
-			Type: "PriorityChanged",
-			Data: map[string]interface{}{"priorityPlayer": g.PriorityPlayer},
-		},
+		g.passPriorityOn(a.PlayerUID),
 	}
 }
 
@@ -371,17 +362,12 @@ func (g *Game) resolveCombat() []Event {
 
 	attackerPlayer := g.Players[g.AttackingPlayer]
 
-	var defenderUID string
-	for uid := range g.Players {
-		if uid != g.AttackingPlayer {
-			defenderUID = uid
-			break
-		}
-	}
-	defender := g.Players[defenderUID]
-
 	// Resolve each attack
 	for _, pa := range g.PendingAttacks {
+		defender := g.Players[pa.TargetPlayerUID]
+		if defender == nil || defender.Eliminated {
+			continue
+		}
 		var attackerCreature *FieldCard
 		for _, fc := range attackerPlayer.Field {
 			if fc.InstanceID == pa.AttackerInstanceID {
@@ -393,23 +379,23 @@ func (g *Game) resolveCombat() []Event {
 			continue
 		}
 
-		attackerCard := CardDB[attackerCreature.CardID]
 		attackerDamage := attackerCreature.GetAttack()
-		attackerHasFirstStrike := attackerCard.HasAbility("FirstStrike") || attackerCard.HasAbility("DoubleStrike")
-		attackerHasDoubleStrike := attackerCard.HasAbility("DoubleStrike")
+		attackerHasFirstStrike := attackerCreature.HasAbility("FirstStrike") || attackerCreature.HasAbility("DoubleStrike")
+		attackerHasDoubleStrike := attackerCreature.HasAbility("DoubleStrike")
 
 		if pa.TargetType == "player" {
-			defender.Life -= attackerDamage
+			g.changeLife(pa.TargetPlayerUID, -attackerDamage)
 			events = append(events, Event{
 				Type: "Damage",
 				Data: map[string]interface{}{
-					"target": pa.TargetPlayerUID,
-					"amount": attackerDamage,
-					"source": attackerCreature.InstanceID,
+					"target":  pa.TargetPlayerUID,
+					"amount":  attackerDamage,
+					"source":  attackerCreature.InstanceID,
+					"newLife": defender.Life,
 				},
 			})
 			if attackerHasDoubleStrike {
-				defender.Life -= attackerDamage
+				g.changeLife(pa.TargetPlayerUID, -attackerDamage)
 				events = append(events, Event{
 					Type: "Damage",
 					Data: map[string]interface{}{
@@ -417,6 +403,7 @@ func (g *Game) resolveCombat() []Event {
 						"amount":       attackerDamage,
 						"source":       attackerCreature.InstanceID,
 						"doubleStrike": true,
+						"newLife":      defender.Life,
 					},
 				})
 			}
@@ -432,10 +419,9 @@ func (g *Game) resolveCombat() []Event {
 				continue
 			}
 
-			targetCard := CardDB[targetCreature.CardID]
 			targetDamage := targetCreature.GetAttack()
-			targetHasFirstStrike := targetCard.HasAbility("FirstStrike") || targetCard.HasAbility("DoubleStrike")
-			targetHasDoubleStrike := targetCard.HasAbility("DoubleStrike")
+			targetHasFirstStrike := targetCreature.HasAbility("FirstStrike") || targetCreature.HasAbility("DoubleStrike")
+			targetHasDoubleStrike := targetCreature.HasAbility("DoubleStrike")
 
 			events = append(events, resolveCombatDamage(
 				attackerCreature, attackerDamage, attackerHasFirstStrike, attackerHasDoubleStrike,
@@ -445,30 +431,12 @@ func (g *Game) resolveCombat() []Event {
 	}
 
 	// Handle deaths and game over
-	events = append(events, g.handleDeaths(attackerPlayer, g.AttackingPlayer)...)
-	events = append(events, g.handleDeaths(defender, defenderUID)...)
-
-	if defender.Life <= 0 {
-		g.Winner = g.AttackingPlayer
-		events = append(events, Event{
-			Type: "GameOver",
-			Data: map[string]interface{}{"winner": g.AttackingPlayer},
-		})
-	}
-	if attackerPlayer.Life <= 0 {
-		g.Winner = defenderUID
-		events = append(events, Event{
-			Type: "GameOver",
-			Data: map[string]interface{}{"winner": defenderUID},
-		})
+	for _, uid := range g.seatOrder() {
+		events = append(events, g.handleDeaths(g.Players[uid], uid)...)
 	}
 
-	// Clear combat state
-	g.CombatPhase = ""
-	g.PendingAttacks = nil
-	g.AttackingPlayer = ""
-	g.PriorityPlayer = ""
-	g.PassedPlayers = nil
+	events = append(events, g.checkGameOver()...)
+	g.clearCombat()
 
 	events = append(events, Event{
 		Type: "CombatEnded",
@@ -478,127 +446,72 @@ func (g *Game) resolveCombat() []Event {
 	return events
 }
 
+// clearCombat ends combat, whether it resolved or was called off
+func (g *Game) clearCombat() {
+	g.CombatPhase = ""
+	g.PendingAttacks = nil
+	g.AttackingPlayer = ""
+	g.PriorityPlayer = ""
+	g.PassedPlayers = nil
+}
+
 // resolveCombatDamage handles damage between two creatures with FirstStrike/DoubleStrike
 func resolveCombatDamage(
 	creature1 *FieldCard, damage1 int, hasFirstStrike1 bool, hasDoubleStrike1 bool,
 	creature2 *FieldCard, damage2 int, hasFirstStrike2 bool, hasDoubleStrike2 bool,
 ) []Event {
 	events := []Event{}
+	firstStrike := map[string]interface{}{"firstStrike": true}
 
 	// First Strike phase
 	if hasFirstStrike1 && hasFirstStrike2 {
-		creature1.CurrentHealth -= damage2
-		creature2.CurrentHealth -= damage1
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature1.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature2.InstanceID,
-				"damage":             damage1,
-				"firstStrike":        true,
-			},
-		})
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature2.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature1.InstanceID,
-				"damage":             damage2,
-				"firstStrike":        true,
-			},
-		})
+		events = append(events, dealCombatDamage(creature1, creature2, damage1, firstStrike)...)
+		events = append(events, dealCombatDamage(creature2, creature1, damage2, firstStrike)...)
 	} else if hasFirstStrike1 && !hasFirstStrike2 {
-		creature2.CurrentHealth -= damage1
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature1.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature2.InstanceID,
-				"damage":             damage1,
-				"firstStrike":        true,
-			},
-		})
+		events = append(events, dealCombatDamage(creature1, creature2, damage1, firstStrike)...)
 	} else if hasFirstStrike2 && !hasFirstStrike1 {
-		creature1.CurrentHealth -= damage2
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature2.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature1.InstanceID,
-				"damage":             damage2,
-				"firstStrike":        true,
-			},
-		})
+		events = append(events, dealCombatDamage(creature2, creature1, damage2, firstStrike)...)
 	}
 
 	// Normal damage phase
 	creature1DealsNormal := (!hasFirstStrike1 || hasDoubleStrike1) && !creature1.IsDead()
 	creature2DealsNormal := (!hasFirstStrike2 || hasDoubleStrike2) && !creature2.IsDead()
 
-	if creature1DealsNormal && creature2DealsNormal {
-		creature1.CurrentHealth -= damage2
-		creature2.CurrentHealth -= damage1
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature1.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature2.InstanceID,
-				"damage":             damage1,
-				"doubleStrike":       hasDoubleStrike1,
-			},
-		})
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature2.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature1.InstanceID,
-				"damage":             damage2,
-				"doubleStrike":       hasDoubleStrike2,
-			},
-		})
-	} else if creature1DealsNormal {
-		creature2.CurrentHealth -= damage1
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature1.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature2.InstanceID,
-				"damage":             damage1,
-				"doubleStrike":       hasDoubleStrike1,
-			},
-		})
-	} else if creature2DealsNormal {
-		creature1.CurrentHealth -= damage2
-		events = append(events, Event{
-			Type: "CombatDamage",
-			Data: map[string]interface{}{
-				"attackerInstanceId": creature2.InstanceID,
-				"targetType":         "creature",
-				"targetInstanceId":   creature1.InstanceID,
-				"damage":             damage2,
-				"doubleStrike":       hasDoubleStrike2,
-			},
-		})
+	if creature1DealsNormal {
+		events = append(events, dealCombatDamage(creature1, creature2, damage1, map[string]interface{}{"doubleStrike": hasDoubleStrike1})...)
+	}
+	if creature2DealsNormal {
+		events = append(events, dealCombatDamage(creature2, creature1, damage2, map[string]interface{}{"doubleStrike": hasDoubleStrike2})...)
 	}
 
 	return events
 }
 
+// dealCombatDamage applies one creature's combat damage to another
+// extra holds strike flags to include in the CombatDamage event
+func dealCombatDamage(source, target *FieldCard, damage int, extra map[string]interface{}) []Event {
+	dealt, statusEvents := target.applyDamage(damage)
+
+	data := map[string]interface{}{
+		"attackerInstanceId": source.InstanceID,
+		"targetType":         "creature",
+		"targetInstanceId":   target.InstanceID,
+		"damage":             dealt,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	return append([]Event{{Type: "CombatDamage", Data: data}}, statusEvents...)
+}
+
 // handleDeaths removes dead creatures from field
 func (g *Game) handleDeaths(p *Player, playerUID string) []Event {
 	events := []Event{}
 	alive := []*FieldCard{}
 	for _, fc := range p.Field {
-		card := CardDB[fc.CardID]
+		card := g.Card(fc.CardID)
 		if card.CardType == "Creature" && fc.IsDead() {
-			p.Discard = append(p.Discard, fc.CardID)
 			events = append(events, Event{
 				Type: "CreatureDied",
 				Data: map[string]interface{}{
@@ -607,10 +520,19 @@ func (g *Game) handleDeaths(p *Player, playerUID string) []Event {
 					"cardId":     fc.CardID,
 				},
 			})
+			// Leaders go back to the leader zone instead of the discard pile
+			if fc.IsLeader {
+				events = append(events, g.returnLeader(p, playerUID, fc, true))
+			} else {
+				p.Discard = append(p.Discard, fc.CardID)
+			}
 		} else {
 			alive = append(alive, fc)
 		}
 	}
 	p.Field = alive
+
+	// Effects on creatures that left the field, or bound to them, go away
+	events = append(events, g.pruneEffects()...)
 	return events
 }
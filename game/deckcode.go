@@ -0,0 +1,141 @@
+// deckcode.go - Compact shareable deck codes
+package game
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+// Deck codes look like "CG" followed by URL-safe base64 of:
+//
+//	version byte
+//	leader card ID (uvarint)
+//	main deck entries, then vault entries, then (version 2) sideboard entries, each as:
+//	    entry count (uvarint), then per entry: ID delta from the previous ID (uvarint), copies (uvarint)
+//	CRC-32 of everything above (4 bytes, big-endian)
+//
+// IDs are sorted and delta-encoded so a typical deck is well under 100 characters.
+// Version 1 codes (no sideboard) are still accepted.
+const (
+	DeckCodePrefix  = "CG"
+	DeckCodeVersion = 2
+)
+
+// EncodeDeck returns the deck code for a deck's leader, main deck, vault and sideboard (the name isn't included)
+func EncodeDeck(deck Deck) string {
+	buf := []byte{DeckCodeVersion}
+	buf = binary.AppendUvarint(buf, uint64(deck.Leader))
+	buf = appendCardCounts(buf, deck.MainDeck)
+	buf = appendCardCounts(buf, deck.Vault)
+	buf = appendCardCounts(buf, deck.Sideboard)
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	return DeckCodePrefix + base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// appendCardCounts writes a card list as sorted (ID delta, copies) entries
+func appendCardCounts(buf []byte, ids []int) []byte {
+	counts := map[int]int{}
+	for _, id := range ids {
+		counts[id]++
+	}
+	unique := uniqueIDs(ids)
+
+	buf = binary.AppendUvarint(buf, uint64(len(unique)))
+	prev := 0
+	for _, id := range unique {
+		buf = binary.AppendUvarint(buf, uint64(id-prev))
+		buf = binary.AppendUvarint(buf, uint64(counts[id]))
+		prev = id
+	}
+	return buf
+}
+
+// DecodeDeck parses a deck code back into a deck (ID and name are left empty)
+// Only the format is checked here; use ValidateDeck to check the cards.
+func DecodeDeck(code string) (Deck, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, DeckCodePrefix) {
+		return Deck{}, fmt.Errorf("invalid deck code: must start with %q", DeckCodePrefix)
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(code[len(DeckCodePrefix):])
+	if err != nil {
+		return Deck{}, fmt.Errorf("invalid deck code: %v", err)
+	}
+	if len(buf) < 5 {
+		return Deck{}, fmt.Errorf("invalid deck code: too short")
+	}
+
+	body, sum := buf[:len(buf)-4], binary.BigEndian.Uint32(buf[len(buf)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return Deck{}, fmt.Errorf("invalid deck code: checksum mismatch (code was mistyped or cut off)")
+	}
+	if body[0] < 1 || body[0] > DeckCodeVersion {
+		return Deck{}, fmt.Errorf("unsupported deck code version %d", body[0])
+	}
+
+	r := &codeReader{buf: body[1:]}
+	deck := Deck{Leader: r.int()}
+	deck.MainDeck = r.cardCounts("main deck", DefaultRules.MaxMainDeckSize)
+	deck.Vault = r.cardCounts("vault", DefaultRules.MaxVaultSize)
+	if body[0] >= 2 {
+		deck.Sideboard = r.cardCounts("sideboard", MaxSideboardSize)
+		if len(deck.Sideboard) == 0 {
+			deck.Sideboard = nil
+		}
+	}
+	if r.err != nil {
+		return Deck{}, fmt.Errorf("invalid deck code: %v", r.err)
+	}
+	if len(r.buf) > 0 {
+		return Deck{}, fmt.Errorf("invalid deck code: %d unexpected trailing bytes", len(r.buf))
+	}
+	return deck, nil
+}
+
+// codeReader reads uvarints from a deck code, remembering the first error
+type codeReader struct {
+	buf []byte
+	err error
+}
+
+func (r *codeReader) int() int {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 || v > 1<<31 {
+		r.err = fmt.Errorf("truncated or corrupt data")
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return int(v)
+}
+
+// cardCounts reads a list of (ID delta, copies) entries, refusing more than limit cards
+// so a forged code can't make the server allocate a huge deck
+func (r *codeReader) cardCounts(section string, limit int) []int {
+	ids := []int{}
+	entries := r.int()
+	if entries > limit {
+		r.err = fmt.Errorf("%s has more than %d cards", section, limit)
+		return nil
+	}
+	id := 0
+	for i := 0; i < entries && r.err == nil; i++ {
+		id += r.int()
+		copies := r.int()
+		if len(ids)+copies > limit {
+			r.err = fmt.Errorf("%s has more than %d cards", section, limit)
+			return nil
+		}
+		for ; copies > 0; copies-- {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
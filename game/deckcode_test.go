@@ -0,0 +1,135 @@
+package game
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDeckCodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		deck Deck
+	}{
+		{
+			name: "main deck and vault",
+			deck: Deck{Leader: 4, MainDeck: []int{3, 1, 2, 2, 1, 3}, Vault: []int{101, 101, 101}},
+		},
+		{
+			name: "with a sideboard",
+			deck: Deck{Leader: 88, MainDeck: []int{76, 77, 77}, Vault: []int{104, 105}, Sideboard: []int{90, 90, 12}},
+		},
+		{
+			name: "large IDs",
+			deck: Deck{Leader: 100000, MainDeck: []int{70000, 1, 70000}, Vault: []int{250}},
+		},
+		{
+			name: "empty",
+			deck: Deck{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := EncodeDeck(tt.deck)
+			if !strings.HasPrefix(code, DeckCodePrefix) {
+				t.Fatalf("code %q is missing the prefix", code)
+			}
+			got, err := DecodeDeck(" " + code + "\n")
+			if err != nil {
+				t.Fatalf("decode %q: %v", code, err)
+			}
+			if got.Leader != tt.deck.Leader {
+				t.Errorf("leader %d, want %d", got.Leader, tt.deck.Leader)
+			}
+			for _, section := range []struct {
+				name      string
+				got, want []int
+			}{
+				{"main deck", got.MainDeck, tt.deck.MainDeck},
+				{"vault", got.Vault, tt.deck.Vault},
+				{"sideboard", got.Sideboard, tt.deck.Sideboard},
+			} {
+				want := slices.Sorted(slices.Values(section.want))
+				if !slices.Equal(section.got, want) {
+					t.Errorf("%s %v, want %v", section.name, section.got, want)
+				}
+			}
+			if len(tt.deck.Sideboard) == 0 && got.Sideboard != nil {
+				t.Errorf("empty sideboard decoded as %v, want nil", got.Sideboard)
+			}
+		})
+	}
+}
+
+func TestDecodeDeckVersion1(t *testing.T) {
+	buf := []byte{1}
+	buf = binary.AppendUvarint(buf, 4)
+	buf = appendCardCounts(buf, []int{1, 1, 2})
+	buf = appendCardCounts(buf, []int{101})
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+
+	deck, err := DecodeDeck(DeckCodePrefix + base64.RawURLEncoding.EncodeToString(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deck.Leader != 4 || !slices.Equal(deck.MainDeck, []int{1, 1, 2}) || !slices.Equal(deck.Vault, []int{101}) || deck.Sideboard != nil {
+		t.Errorf("decoded %+v", deck)
+	}
+}
+
+func TestDecodeDeckRejects(t *testing.T) {
+	code := EncodeDeck(Deck{Leader: 4, MainDeck: []int{1, 2, 3}, Vault: []int{101, 101}})
+	raw, _ := base64.RawURLEncoding.DecodeString(code[len(DeckCodePrefix):])
+
+	// flipped changes one bit of the body, which the checksum must catch
+	flipped := slices.Clone(raw)
+	flipped[2] ^= 0x01
+
+	// badVersion has a valid checksum over an unknown version
+	badVersion := slices.Clone(raw[:len(raw)-4])
+	badVersion[0] = DeckCodeVersion + 1
+	badVersion = binary.BigEndian.AppendUint32(badVersion, crc32.ChecksumIEEE(badVersion))
+
+	// trailing has a valid checksum over extra bytes after the sections
+	trailing := append(slices.Clone(raw[:len(raw)-4]), 0)
+	trailing = binary.BigEndian.AppendUint32(trailing, crc32.ChecksumIEEE(trailing))
+
+	// huge claims more main deck cards than any deck may have
+	huge := []byte{DeckCodeVersion}
+	huge = binary.AppendUvarint(huge, 4)
+	huge = binary.AppendUvarint(huge, 1)
+	huge = binary.AppendUvarint(huge, 1)
+	huge = binary.AppendUvarint(huge, 1<<30)
+	huge = binary.BigEndian.AppendUint32(huge, crc32.ChecksumIEEE(huge))
+
+	encode := func(b []byte) string {
+		return DeckCodePrefix + base64.RawURLEncoding.EncodeToString(b)
+	}
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"wrong prefix", "XX" + code[len(DeckCodePrefix):], "must start with"},
+		{"not base64", DeckCodePrefix + "!!!", "invalid deck code"},
+		{"too short", encode([]byte{DeckCodeVersion, 0}), "too short"},
+		{"checksum mismatch", encode(flipped), "checksum mismatch"},
+		{"cut off", code[:len(code)-3], "invalid deck code"},
+		{"unknown version", encode(badVersion), "unsupported deck code version"},
+		{"trailing bytes", encode(trailing), "trailing bytes"},
+		{"too many cards", encode(huge), "more than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeDeck(tt.code)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
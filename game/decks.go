@@ -6,8 +6,8 @@ import (
 	"os"
 )
 
-const MaxMainDeckSize = 30
-const MaxVaultSize = 15
+// Deck sizes and copy limits are part of the rule set (see DefaultRules)
+const MaxSideboardSize = 10
 
 type Deck struct {
 	ID       int    `json:"ID"`
@@ -15,27 +15,41 @@ type Deck struct {
 	Leader   int    `json:"Leader"`   // Card ID of the leader
 	MainDeck []int  `json:"MainDeck"` // Creature cards
 	Vault    []int  `json:"Vault"`    // Land cards
+	Owner    string `json:"Owner,omitempty"` // Player UID for custom decks (empty for built-in decks)
+
+	// Cards that can be swapped into MainDeck or Vault between games of a match
+	Sideboard []int `json:"Sideboard,omitempty"`
 }
 
+// DeckDB holds the decks loaded at startup; see CurrentPool for the live data
 var DeckDB = map[int]Deck{}
 
-func LoadDecks(path string) error {
+// ReadDecks parses a decks file without loading it into DeckDB
+func ReadDecks(path string) ([]Deck, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var decks []Deck
 	if err := json.Unmarshal(data, &decks); err != nil {
+		return nil, err
+	}
+	return decks, nil
+}
+
+func LoadDecks(path string) error {
+	decks, err := ReadDecks(path)
+	if err != nil {
 		return err
 	}
 
 	for _, deck := range decks {
-		if len(deck.MainDeck) > MaxMainDeckSize {
-			return fmt.Errorf("deck %q has %d main deck cards, max is %d", deck.Name, len(deck.MainDeck), MaxMainDeckSize)
+		if len(deck.MainDeck) > DefaultRules.MaxMainDeckSize {
+			return fmt.Errorf("deck %q has %d main deck cards, max is %d", deck.Name, len(deck.MainDeck), DefaultRules.MaxMainDeckSize)
 		}
-		if len(deck.Vault) > MaxVaultSize {
-			return fmt.Errorf("deck %q has %d vault cards, max is %d", deck.Name, len(deck.Vault), MaxVaultSize)
+		if len(deck.Vault) > DefaultRules.MaxVaultSize {
+			return fmt.Errorf("deck %q has %d vault cards, max is %d", deck.Name, len(deck.Vault), DefaultRules.MaxVaultSize)
 		}
 		DeckDB[deck.ID] = deck
 	}
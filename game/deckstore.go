@@ -0,0 +1,227 @@
+// deckstore.go - Player-built decks, saved on disk with one file per player
+package game
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CustomDeckIDStart is the first ID given to player-built decks, well above the built-in deck IDs
+const CustomDeckIDStart = 10000
+
+// MaxDecksPerPlayer limits how many custom decks one player can save
+const MaxDecksPerPlayer = 20
+
+// DeckStore holds every player's custom decks and writes them to disk on change
+type DeckStore struct {
+	mu     sync.Mutex
+	dir    string
+	decks  map[string][]Deck // By owner UID
+	nextID int
+}
+
+// PlayerDecks is the store used by the server (loaded at startup)
+var PlayerDecks = NewDeckStore("data/player_decks")
+
+// NewDeckStore creates an empty store that saves into dir
+func NewDeckStore(dir string) *DeckStore {
+	return &DeckStore{
+		dir:    dir,
+		decks:  make(map[string][]Deck),
+		nextID: CustomDeckIDStart,
+	}
+}
+
+// Load reads every player's deck file from the store's directory
+// A missing directory is not an error; it's created on the first save.
+func (ds *DeckStore) Load() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(ds.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var decks []Deck
+		if err := json.Unmarshal(data, &decks); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		for _, deck := range decks {
+			ds.decks[deck.Owner] = append(ds.decks[deck.Owner], deck)
+			if deck.ID >= ds.nextID {
+				ds.nextID = deck.ID + 1
+			}
+		}
+	}
+	return nil
+}
+
+// List returns a copy of the player's custom decks
+func (ds *DeckStore) List(owner string) []Deck {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return append([]Deck{}, ds.decks[owner]...)
+}
+
+// Get returns one of the player's custom decks
+func (ds *DeckStore) Get(owner string, deckID int) (Deck, bool) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	for _, deck := range ds.decks[owner] {
+		if deck.ID == deckID {
+			return deck, true
+		}
+	}
+	return Deck{}, false
+}
+
+// Save validates a new deck against the current pool and stores it under a fresh ID
+func (ds *DeckStore) Save(owner string, deck Deck) (Deck, error) {
+	if err := checkCustomDeck(owner, &deck); err != nil {
+		return Deck{}, err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if len(ds.decks[owner]) >= MaxDecksPerPlayer {
+		return Deck{}, fmt.Errorf("deck limit reached (%d)", MaxDecksPerPlayer)
+	}
+	deck.ID = ds.nextID
+	deck.Owner = owner
+
+	decks := append(append([]Deck{}, ds.decks[owner]...), deck)
+	if err := ds.write(owner, decks); err != nil {
+		return Deck{}, err
+	}
+	ds.nextID++
+	ds.decks[owner] = decks
+	return deck, nil
+}
+
+// Update replaces one of the player's decks (matched by deck.ID) after validating it
+func (ds *DeckStore) Update(owner string, deck Deck) (Deck, error) {
+	if err := checkCustomDeck(owner, &deck); err != nil {
+		return Deck{}, err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	decks := append([]Deck{}, ds.decks[owner]...)
+	for i := range decks {
+		if decks[i].ID == deck.ID {
+			deck.Owner = owner
+			decks[i] = deck
+			if err := ds.write(owner, decks); err != nil {
+				return Deck{}, err
+			}
+			ds.decks[owner] = decks
+			return deck, nil
+		}
+	}
+	return Deck{}, fmt.Errorf("deck not found: %d", deck.ID)
+}
+
+// Delete removes one of the player's decks
+func (ds *DeckStore) Delete(owner string, deckID int) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	decks := []Deck{}
+	found := false
+	for _, deck := range ds.decks[owner] {
+		if deck.ID == deckID {
+			found = true
+			continue
+		}
+		decks = append(decks, deck)
+	}
+	if !found {
+		return fmt.Errorf("deck not found: %d", deckID)
+	}
+	if err := ds.write(owner, decks); err != nil {
+		return err
+	}
+	ds.decks[owner] = decks
+	return nil
+}
+
+// write saves the player's decks to their file (caller holds ds.mu)
+// File names are the hex-encoded UID so any UID is a safe file name.
+func (ds *DeckStore) write(owner string, decks []Deck) error {
+	data, err := json.MarshalIndent(decks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(ds.dir, hex.EncodeToString([]byte(owner))+".json"), data)
+}
+
+// checkCustomDeck tidies a submitted deck and validates it against the current pool
+func checkCustomDeck(owner string, deck *Deck) error {
+	if owner == "" {
+		return fmt.Errorf("missing player UID")
+	}
+	deck.Name = strings.TrimSpace(deck.Name)
+	if deck.Name == "" {
+		return fmt.Errorf("deck needs a name")
+	}
+	if len(deck.Name) > 40 {
+		return fmt.Errorf("deck name too long (max 40 characters)")
+	}
+	if len(deck.MainDeck) == 0 {
+		return fmt.Errorf("deck has no main deck cards")
+	}
+	return ValidateDeck(*deck, CurrentPool())
+}
+
+// LookupDeck finds a built-in deck in the current pool or one of the player's custom decks
+func LookupDeck(playerUID string, deckID int) (Deck, bool) {
+	if deck, ok := CurrentPool().Decks[deckID]; ok {
+		return deck, true
+	}
+	return PlayerDecks.Get(playerUID, deckID)
+}
+
+// ImportDeck decodes a deck code, checks it against the current pool and saves it as a custom deck
+func ImportDeck(playerUID, code, name string) (Deck, error) {
+	deck, err := DecodeDeck(code)
+	if err != nil {
+		return Deck{}, err
+	}
+	if err := ValidateDeck(deck, CurrentPool()); err != nil {
+		return Deck{}, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Imported deck"
+	}
+	deck.Name = name
+	return PlayerDecks.Save(playerUID, deck)
+}
+
+// findDeck looks up a deck a player can use in a game: a built-in deck from the pool,
+// or one of the player's own custom decks (checked against the pool, since cards may
+// have changed since it was saved)
+func findDeck(pool *CardPool, rules *RuleSet, playerUID string, deckID int) (Deck, error) {
+	deck, ok := pool.Decks[deckID]
+	if !ok {
+		deck, ok = PlayerDecks.Get(playerUID, deckID)
+	}
+	if !ok {
+		return Deck{}, fmt.Errorf("deck not found: %d", deckID)
+	}
+	if err := rules.ValidateDeck(deck, pool); err != nil {
+		return Deck{}, err
+	}
+	return deck, nil
+}
@@ -0,0 +1,609 @@
+// draft.go - Limited formats: booster draft with bot drafters, sealed, and building a deck from your picks
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Limited formats
+const (
+	FormatDraft  = "draft"  // Pick a card from a pack and pass the rest on
+	FormatSealed = "sealed" // Open SealedPacks packs and build from all of them
+)
+
+const (
+	MinDraftSeats  = 2
+	MaxDraftSeats  = 8
+	DraftRounds    = 3  // Packs each drafter opens, passed left, right, then left again
+	SealedPacks    = 6  // Packs each sealed player opens
+	DraftVaultSize = 15 // Lands added to a limited deck's vault
+)
+
+// Draft phases
+const (
+	DraftLobby    = "lobby"    // Waiting for players; the host starts it
+	DraftPicking  = "drafting" // Picking and passing packs
+	DraftBuilding = "building" // Everyone builds a deck from their picks
+)
+
+// DraftSeat is one drafter: a player or a bot filling an empty seat
+type DraftSeat struct {
+	UID   string
+	Bot   bool
+	Picks []int   // Cards taken so far (every opened card in sealed)
+	packs [][]int // Packs passed to this seat, the one to pick from first
+	Built bool    // A deck was built from the picks
+}
+
+// Draft is one booster draft or sealed event
+type Draft struct {
+	mu     sync.Mutex
+	ID     string
+	Format string
+	Host   string
+	Size   int // Seats at the table; empty seats get bots when the draft starts
+	Seats  []*DraftSeat
+	Round  int // Pack being drafted, from 1 (0 before the draft starts)
+	Phase  string
+	pool   *CardPool
+}
+
+// DraftSeatInfo is what everyone can see about a seat
+type DraftSeatInfo struct {
+	UID     string `json:"uid"`
+	Bot     bool   `json:"bot"`
+	Picks   int    `json:"picks"`
+	Waiting int    `json:"waiting"` // Packs waiting to be picked from
+	Built   bool   `json:"built"`
+}
+
+// DraftView is one player's view of a draft
+type DraftView struct {
+	DraftID string          `json:"draftId"`
+	Format  string          `json:"format"`
+	Phase   string          `json:"phase"`
+	Host    string          `json:"host"`
+	Size    int             `json:"size"`
+	Round   int             `json:"round"`
+	Rounds  int             `json:"rounds"`
+	Seats   []DraftSeatInfo `json:"seats"`
+	Pack    []int           `json:"pack"`  // The pack to pick from now (empty while waiting)
+	Picks   []int           `json:"picks"` // Cards picked so far
+}
+
+// DraftManager keeps the drafts in progress
+type DraftManager struct {
+	mu     sync.Mutex
+	drafts map[string]*Draft
+	nextID int
+}
+
+// Drafts is the draft manager used by the server
+var Drafts = &DraftManager{drafts: make(map[string]*Draft), nextID: 1}
+
+// CreateDraft opens a draft or sealed lobby with the given number of seats
+func (dm *DraftManager) CreateDraft(hostUID, format string, seats int) (*Draft, error) {
+	if format != FormatDraft && format != FormatSealed {
+		return nil, fmt.Errorf("unknown limited format %q (draft or sealed)", format)
+	}
+	if seats < MinDraftSeats || seats > MaxDraftSeats {
+		return nil, fmt.Errorf("drafts have %d to %d seats, not %d", MinDraftSeats, MaxDraftSeats, seats)
+	}
+
+	pool := CurrentPool()
+	if len(packCards(pool)) == 0 {
+		return nil, fmt.Errorf("the card pool has no cards that can be opened in packs")
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if dm.playerDraft(hostUID) != nil {
+		return nil, fmt.Errorf("already in a draft")
+	}
+
+	d := &Draft{
+		ID:     fmt.Sprintf("draft_%d", dm.nextID),
+		Format: format,
+		Host:   hostUID,
+		Size:   seats,
+		Seats:  []*DraftSeat{{UID: hostUID}},
+		Phase:  DraftLobby,
+		pool:   pool,
+	}
+	dm.nextID++
+	dm.drafts[d.ID] = d
+	return d, nil
+}
+
+// GetDraft returns a draft by ID
+func (dm *DraftManager) GetDraft(draftID string) *Draft {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.drafts[draftID]
+}
+
+// PlayerDraft returns the draft a player is seated in, if any
+func (dm *DraftManager) PlayerDraft(uid string) *Draft {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	return dm.playerDraft(uid)
+}
+
+// playerDraft finds the draft a player is seated in (caller holds dm.mu)
+// Players who already built their deck, or left and got replaced by a bot, are free to join another one.
+func (dm *DraftManager) playerDraft(uid string) *Draft {
+	for _, d := range dm.drafts {
+		d.mu.Lock()
+		seat := d.seat(uid)
+		d.mu.Unlock()
+		if seat != nil && !seat.Bot && !seat.Built {
+			return d
+		}
+	}
+	return nil
+}
+
+// JoinDraft takes a free seat in a draft that hasn't started
+func (dm *DraftManager) JoinDraft(draftID, uid string) (*Draft, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	d, ok := dm.drafts[draftID]
+	if !ok {
+		return nil, fmt.Errorf("draft not found")
+	}
+	if dm.playerDraft(uid) != nil {
+		return nil, fmt.Errorf("already in a draft")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Phase != DraftLobby {
+		return nil, fmt.Errorf("draft has already started")
+	}
+	if len(d.Seats) >= d.Size {
+		return nil, fmt.Errorf("draft is full")
+	}
+	d.Seats = append(d.Seats, &DraftSeat{UID: uid})
+	return d, nil
+}
+
+// StartDraft fills the empty seats with bots and opens the first packs
+// Sealed players open all their packs at once and go straight to deck building.
+func (dm *DraftManager) StartDraft(draftID, hostUID string) (*Draft, error) {
+	d := dm.GetDraft(draftID)
+	if d == nil {
+		return nil, fmt.Errorf("draft not found")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Host != hostUID {
+		return nil, fmt.Errorf("only the host can do that")
+	}
+	if d.Phase != DraftLobby {
+		return nil, fmt.Errorf("draft has already started")
+	}
+
+	for n := 1; len(d.Seats) < d.Size; n++ {
+		d.Seats = append(d.Seats, &DraftSeat{UID: fmt.Sprintf("bot_%d", n), Bot: true})
+	}
+
+	if d.Format == FormatSealed {
+		for _, seat := range d.Seats {
+			for n := 0; n < SealedPacks; n++ {
+				seat.Picks = append(seat.Picks, OpenPack(d.pool)...)
+			}
+		}
+		d.Phase = DraftBuilding
+		return d, nil
+	}
+
+	d.Phase = DraftPicking
+	d.openRound(1)
+	return d, nil
+}
+
+// LeaveDraft takes a player out of a draft, reporting whether that closed its lobby
+// Leaving a lobby frees the seat (the host leaving closes it); leaving mid-draft hands the seat to a bot.
+func (dm *DraftManager) LeaveDraft(uid string) (d *Draft, closed bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	d = dm.playerDraft(uid)
+	if d == nil {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch d.Phase {
+	case DraftLobby:
+		if uid == d.Host {
+			delete(dm.drafts, d.ID)
+			return d, true
+		}
+		for i, seat := range d.Seats {
+			if seat.UID == uid {
+				d.Seats = append(d.Seats[:i], d.Seats[i+1:]...)
+				break
+			}
+		}
+	case DraftPicking:
+		d.seat(uid).Bot = true
+		d.runBots()
+	default:
+		d.seat(uid).Built = true
+	}
+	dm.removeFinished(d)
+	return d, false
+}
+
+// removeFinished forgets a draft once no player is left to build a deck from it (caller holds dm.mu and d.mu)
+func (dm *DraftManager) removeFinished(d *Draft) {
+	for _, seat := range d.Seats {
+		if !seat.Bot && !seat.Built {
+			return
+		}
+	}
+	delete(dm.drafts, d.ID)
+}
+
+// seat returns a player's seat (caller holds d.mu)
+// Bot seats are never matched: their names aren't reserved, so a player could share one.
+func (d *Draft) seat(uid string) *DraftSeat {
+	for _, seat := range d.Seats {
+		if !seat.Bot && seat.UID == uid {
+			return seat
+		}
+	}
+	return nil
+}
+
+// Players returns the UIDs of the players (not bots) at the table
+func (d *Draft) Players() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	uids := []string{}
+	for _, seat := range d.Seats {
+		if !seat.Bot {
+			uids = append(uids, seat.UID)
+		}
+	}
+	return uids
+}
+
+// openRound gives every seat a new pack and lets the bots pick (caller holds d.mu)
+// Empty packs are never handed out: nobody could pick from them.
+func (d *Draft) openRound(round int) {
+	d.Round = round
+	for _, seat := range d.Seats {
+		if pack := OpenPack(d.pool); len(pack) > 0 {
+			seat.packs = append(seat.packs, pack)
+		}
+	}
+	d.runBots()
+}
+
+// passTo returns the seat index a pack goes to next: left in odd rounds, right in even ones
+func (d *Draft) passTo(from int) int {
+	if d.Round%2 == 1 {
+		return (from + 1) % len(d.Seats)
+	}
+	return (from - 1 + len(d.Seats)) % len(d.Seats)
+}
+
+// Pick takes a card from the pack in front of the player and passes the rest on
+func (d *Draft) Pick(uid string, cardID int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Phase != DraftPicking {
+		return fmt.Errorf("not drafting")
+	}
+	seat := d.seat(uid)
+	if seat == nil {
+		return fmt.Errorf("not in this draft")
+	}
+	if len(seat.packs) == 0 {
+		return fmt.Errorf("waiting for a pack to be passed to you")
+	}
+	if !d.take(seat, cardID) {
+		return fmt.Errorf("card %d is not in your pack", cardID)
+	}
+	d.runBots()
+	return nil
+}
+
+// take moves a card from the seat's current pack into its picks and passes the pack on (caller holds d.mu)
+func (d *Draft) take(seat *DraftSeat, cardID int) bool {
+	pack := seat.packs[0]
+	for i, id := range pack {
+		if id != cardID {
+			continue
+		}
+		seat.Picks = append(seat.Picks, id)
+		seat.packs = seat.packs[1:]
+		rest := append(append([]int{}, pack[:i]...), pack[i+1:]...)
+		if len(rest) > 0 {
+			next := d.Seats[d.passTo(d.seatIndex(seat))]
+			next.packs = append(next.packs, rest)
+		}
+		return true
+	}
+	return false
+}
+
+func (d *Draft) seatIndex(seat *DraftSeat) int {
+	for i, s := range d.Seats {
+		if s == seat {
+			return i
+		}
+	}
+	return 0
+}
+
+// runBots lets bots pick until every pack waits on a player, then moves on to the next round
+// or to deck building once all the packs are empty (caller holds d.mu)
+func (d *Draft) runBots() {
+	for {
+		picked := false
+		for _, seat := range d.Seats {
+			if seat.Bot && len(seat.packs) > 0 {
+				d.take(seat, botPick(d.pool, seat.Picks, seat.packs[0]))
+				picked = true
+			}
+		}
+		if picked {
+			continue
+		}
+
+		for _, seat := range d.Seats {
+			if len(seat.packs) > 0 {
+				return
+			}
+		}
+		if d.Round < DraftRounds {
+			d.openRound(d.Round + 1)
+		} else {
+			d.Phase = DraftBuilding
+		}
+		return
+	}
+}
+
+// botPick picks the rarest card in the pack, preferring the two colors the bot has picked most
+func botPick(pool *CardPool, picks []int, pack []int) int {
+	pips := colorPips(pool, picks)
+	colors := []string{}
+	for color := range pips {
+		colors = append(colors, color)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if pips[colors[i]] != pips[colors[j]] {
+			return pips[colors[i]] > pips[colors[j]]
+		}
+		return colors[i] < colors[j]
+	})
+	if len(colors) > 2 {
+		colors = colors[:2]
+	}
+
+	best, bestScore := pack[0], -1
+	for _, id := range pack {
+		card := pool.Card(id)
+		score := rarityRank(card.PackRarity()) * 10
+		cost := colorPips(pool, []int{id})
+		for _, color := range colors {
+			score += cost[color] * 3
+		}
+		if score > bestScore {
+			best, bestScore = id, score
+		}
+	}
+	return best
+}
+
+// colorPips counts the colored mana symbols in the cards' costs, by color
+// A hybrid symbol counts once toward each of its colors, since either can pay it.
+func colorPips(pool *CardPool, cards []int) map[string]int {
+	pips := map[string]int{}
+	for _, id := range cards {
+		cost := pool.Card(id).Cost
+		options := []ManaCost{cost}
+		for _, symbol := range cost.Hybrid {
+			if parsed, err := parseHybrid(symbol); err == nil {
+				options = append(options, parsed...)
+			}
+		}
+		for _, option := range options {
+			for _, color := range []string{"White", "Blue", "Black", "Red", "Green"} {
+				if n := option.amountOf(color); n > 0 {
+					pips[color] += n
+				}
+			}
+		}
+	}
+	return pips
+}
+
+// View returns what a player sees of the draft
+func (d *Draft) View(uid string) DraftView {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	view := DraftView{
+		DraftID: d.ID,
+		Format:  d.Format,
+		Phase:   d.Phase,
+		Host:    d.Host,
+		Size:    d.Size,
+		Round:   d.Round,
+		Rounds:  DraftRounds,
+		Seats:   []DraftSeatInfo{},
+		Pack:    []int{},
+		Picks:   []int{},
+	}
+	for _, seat := range d.Seats {
+		view.Seats = append(view.Seats, DraftSeatInfo{
+			UID:     seat.UID,
+			Bot:     seat.Bot,
+			Picks:   len(seat.Picks),
+			Waiting: len(seat.packs),
+			Built:   seat.Built,
+		})
+	}
+	if seat := d.seat(uid); seat != nil {
+		view.Picks = append(view.Picks, seat.Picks...)
+		if len(seat.packs) > 0 {
+			view.Pack = append(view.Pack, seat.packs[0]...)
+		}
+	}
+	return view
+}
+
+// BuildDraftDeck saves a custom deck built from a player's picks, with a vault of lands added to match its colors
+// The leader and main deck must come from the picks; cards can be left out.
+func (dm *DraftManager) BuildDraftDeck(draftID, uid string, deck Deck) (Deck, error) {
+	d := dm.GetDraft(draftID)
+	if d == nil {
+		return Deck{}, fmt.Errorf("draft not found")
+	}
+
+	d.mu.Lock()
+	seat := d.seat(uid)
+	switch {
+	case seat == nil || seat.Bot:
+		d.mu.Unlock()
+		return Deck{}, fmt.Errorf("not in this draft")
+	case d.Phase != DraftBuilding:
+		d.mu.Unlock()
+		return Deck{}, fmt.Errorf("the draft isn't over yet")
+	case seat.Built:
+		d.mu.Unlock()
+		return Deck{}, fmt.Errorf("you already built a deck from this draft")
+	}
+	available := map[int]int{}
+	for _, id := range seat.Picks {
+		available[id]++
+	}
+	for _, id := range append([]int{deck.Leader}, deck.MainDeck...) {
+		if available[id] == 0 {
+			d.mu.Unlock()
+			return Deck{}, fmt.Errorf("card %d is not in your picks (or used too many times)", id)
+		}
+		available[id]--
+	}
+	// Claim the build before saving so a second request can't save another deck meanwhile
+	seat.Built = true
+	pool := d.pool
+	d.mu.Unlock()
+
+	if deck.Name == "" {
+		deck.Name = fmt.Sprintf("%s deck (%s)", d.Format, d.ID)
+	}
+	deck.Vault = limitedVault(pool, append([]int{deck.Leader}, deck.MainDeck...), DraftVaultSize)
+	deck.Sideboard = nil
+
+	saved, err := PlayerDecks.Save(uid, deck)
+	if err != nil {
+		d.mu.Lock()
+		seat.Built = false
+		d.mu.Unlock()
+		return Deck{}, err
+	}
+
+	dm.mu.Lock()
+	d.mu.Lock()
+	dm.removeFinished(d)
+	d.mu.Unlock()
+	dm.mu.Unlock()
+	return saved, nil
+}
+
+// limitedVault builds a vault of size lands split between the deck's colors by their mana symbols
+// Each color uses its basic land, or a land that can make that color if it has no basic.
+func limitedVault(pool *CardPool, cards []int, size int) []int {
+	pips := colorPips(pool, cards)
+	colors := []string{}
+	total := 0
+	for color, n := range pips {
+		if landFor(pool, color) != 0 {
+			colors = append(colors, color)
+			total += n
+		}
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		if pips[colors[i]] != pips[colors[j]] {
+			return pips[colors[i]] > pips[colors[j]]
+		}
+		return colors[i] < colors[j]
+	})
+	if len(colors) == 0 {
+		// Colorless decks (or colors with no land) still need mana
+		if land := landFor(pool, "Colorless"); land != 0 {
+			return repeatCard(land, size)
+		}
+		return []int{}
+	}
+
+	// Largest remainder split, so the counts add up to size exactly
+	vault := []int{}
+	counts := map[string]int{}
+	given := 0
+	for _, color := range colors {
+		counts[color] = size * pips[color] / total
+		given += counts[color]
+	}
+	sort.SliceStable(colors, func(i, j int) bool {
+		return size*pips[colors[i]]%total > size*pips[colors[j]]%total
+	})
+	for i := 0; given < size; i++ {
+		counts[colors[i%len(colors)]]++
+		given++
+	}
+	for _, color := range colors {
+		vault = append(vault, repeatCard(landFor(pool, color), counts[color])...)
+	}
+	return vault
+}
+
+// landFor finds the land a limited vault uses for a color: the basic land that makes only that color,
+// or else the lowest-ID untapped land with it among its choices
+func landFor(pool *CardPool, color string) int {
+	ids := []int{}
+	for id, card := range pool.Cards {
+		if card.CardType == "Land" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	fallback := 0
+	for _, id := range ids {
+		card := pool.Card(id)
+		options := card.ManaOptions()
+		if card.EntersTapped {
+			continue
+		}
+		if len(options) == 1 && options[0].amountOf(color) > 0 && options[0].Total() == options[0].amountOf(color) {
+			return id
+		}
+		for _, option := range options {
+			if fallback == 0 && option.amountOf(color) > 0 {
+				fallback = id
+			}
+		}
+	}
+	return fallback
+}
+
+func repeatCard(id, n int) []int {
+	cards := []int{}
+	for i := 0; i < n; i++ {
+		cards = append(cards, id)
+	}
+	return cards
+}
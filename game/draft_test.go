@@ -0,0 +1,87 @@
+package game
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestLimitedVault(t *testing.T) {
+	// gateOnly has no basic Island, so blue comes from the gate
+	gateOnly := &CardPool{Cards: map[int]Card{
+		1:  testPool.Card(1),
+		7:  testPool.Card(7),
+		21: testPool.Card(21),
+	}}
+
+	tests := []struct {
+		name  string
+		pool  *CardPool
+		cards []int
+		size  int
+		want  map[int]int // Land ID -> copies
+	}{
+		{
+			name:  "one color",
+			cards: []int{20, 20},
+			size:  15,
+			want:  map[int]int{5: 15},
+		},
+		{
+			name:  "split by mana symbols",
+			cards: []int{22, 22, 21},
+			size:  15,
+			want:  map[int]int{1: 10, 2: 5},
+		},
+		{
+			name:  "largest remainder gets the spare land",
+			cards: []int{22, 22, 22, 20, 20},
+			size:  16,
+			want:  map[int]int{1: 10, 5: 6},
+		},
+		{
+			name:  "equal remainders go to the first color by name",
+			cards: []int{22, 21, 26},
+			size:  16,
+			want:  map[int]int{3: 6, 2: 5, 1: 5},
+		},
+		{
+			name:  "hybrid symbols count for both colors",
+			cards: []int{24},
+			size:  15,
+			want:  map[int]int{2: 8, 1: 7},
+		},
+		{
+			name:  "colorless deck",
+			cards: []int{27},
+			size:  15,
+			want:  map[int]int{6: 15},
+		},
+		{
+			name:  "no basic land for a color",
+			pool:  gateOnly,
+			cards: []int{21},
+			size:  15,
+			want:  map[int]int{7: 15},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := tt.pool
+			if pool == nil {
+				pool = testPool
+			}
+			vault := limitedVault(pool, tt.cards, tt.size)
+			if len(vault) != tt.size {
+				t.Errorf("%d lands, want %d", len(vault), tt.size)
+			}
+			got := map[int]int{}
+			for _, id := range vault {
+				got[id]++
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("vault %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// drain.go - Drain mode: no new games start while the running ones finish before a restart
+package game
+
+import "errors"
+
+var errDraining = errors.New("the server is restarting soon and isn't starting new games")
+
+// SetDraining turns drain mode on or off
+func (gm *GameManager) SetDraining(on bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.draining = on
+}
+
+// Draining reports whether the server is draining
+func (gm *GameManager) Draining() bool {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.draining
+}
+
+// RunningGames counts the games a restart would cut short: games being played
+// and matches between games. Lobbies still waiting for players don't count.
+func (gm *GameManager) RunningGames() int {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	running := 0
+	for _, g := range gm.games {
+		g.mu.Lock()
+		playing := g.Winner == "" && (g.Started || g.MulliganPhase)
+		g.mu.Unlock()
+		if playing {
+			running++
+			continue
+		}
+		if m := gm.matches[g.MatchID]; m != nil {
+			m.mu.Lock()
+			if m.Phase != MatchOver {
+				running++
+			}
+			m.mu.Unlock()
+		}
+	}
+	return running
+}
@@ -0,0 +1,155 @@
+// effects.go - Duration-based effects: temporary stat modifiers and their expiry
+package game
+
+import "fmt"
+
+// Effect durations
+const (
+	DurationPermanent   = "permanent"    // Never expires (folded into the card's modifiers)
+	DurationEndOfTurn   = "end_of_turn"  // Expires when the current turn ends
+	DurationTurns       = "turns"        // Expires after N of the controller's turns
+	DurationWhileSource = "while_source" // Expires when the source leaves the field
+)
+
+// Effect is a stat modifier applied to a field card for a limited duration
+type Effect struct {
+	ID               int    `json:"id"`
+	TargetInstanceID int    `json:"targetInstanceId"` // Field card the effect applies to
+	SourceInstanceID int    `json:"sourceInstanceId"` // Field card that created it (0 for spells)
+	Controller       string `json:"controller"`       // UID of the player who created the effect
+	AttackMod        int    `json:"attackMod"`
+	HealthMod        int    `json:"healthMod"`
+	Duration         string `json:"duration"`
+	TurnsRemaining   int    `json:"turnsRemaining"` // Only used for DurationTurns
+}
+
+// parseDuration normalizes a script duration argument
+func parseDuration(ref string) (string, error) {
+	switch ref {
+	case "", "permanent":
+		return DurationPermanent, nil
+	case "end_of_turn", "eot", "until_end_of_turn":
+		return DurationEndOfTurn, nil
+	case "turns":
+		return DurationTurns, nil
+	case "while_source", "while_on_field":
+		return DurationWhileSource, nil
+	default:
+		return "", fmt.Errorf("invalid duration: %s", ref)
+	}
+}
+
+// addEffect registers a new effect and applies it to its target
+func (g *Game) addEffect(e *Effect) {
+	g.NextEffectID++
+	e.ID = g.NextEffectID
+	g.Effects = append(g.Effects, e)
+
+	if target, _ := g.findFieldCard(e.TargetInstanceID); target != nil {
+		target.CurrentHealth += e.HealthMod
+	}
+	g.recomputeEffects()
+}
+
+// recomputeEffects rebuilds every field card's effect modifiers from the active effects
+func (g *Game) recomputeEffects() {
+	for _, p := range g.Players {
+		for _, fc := range p.Field {
+			fc.EffectAttack = 0
+			fc.EffectHealth = 0
+		}
+	}
+	for _, e := range g.Effects {
+		if target, _ := g.findFieldCard(e.TargetInstanceID); target != nil {
+			target.EffectAttack += e.AttackMod
+			target.EffectHealth += e.HealthMod
+		}
+	}
+}
+
+// expireEffect removes an effect, undoes its health bonus and returns the expiry event
+func (g *Game) expireEffect(e *Effect, reason string) Event {
+	remaining := []*Effect{}
+	for _, other := range g.Effects {
+		if other.ID != e.ID {
+			remaining = append(remaining, other)
+		}
+	}
+	g.Effects = remaining
+
+	data := map[string]interface{}{
+		"effectId":         e.ID,
+		"targetInstanceId": e.TargetInstanceID,
+		"attackMod":        e.AttackMod,
+		"healthMod":        e.HealthMod,
+		"duration":         e.Duration,
+		"reason":           reason,
+	}
+
+	if target, _ := g.findFieldCard(e.TargetInstanceID); target != nil {
+		target.CurrentHealth -= e.HealthMod
+		g.recomputeEffects()
+		data["newAttack"] = target.GetAttack()
+		data["newMaxHealth"] = target.GetMaxHealth()
+		data["newHealth"] = target.CurrentHealth
+	} else {
+		g.recomputeEffects()
+	}
+
+	return Event{Type: "EffectExpired", Data: data}
+}
+
+// expireTurnEffects ends effects that last until end of turn and counts down
+// turn-based effects controlled by the player whose turn is ending
+func (g *Game) expireTurnEffects(endingPlayer string) []Event {
+	events := []Event{}
+	expired := []*Effect{}
+
+	for _, e := range g.Effects {
+		switch e.Duration {
+		case DurationEndOfTurn:
+			expired = append(expired, e)
+		case DurationTurns:
+			if e.Controller == endingPlayer {
+				e.TurnsRemaining--
+				if e.TurnsRemaining <= 0 {
+					expired = append(expired, e)
+				}
+			}
+		}
+	}
+
+	for _, e := range expired {
+		events = append(events, g.expireEffect(e, "duration_ended"))
+	}
+	return events
+}
+
+// pruneEffects drops effects whose target left the field and expires
+// source-bound effects whose source is gone
+func (g *Game) pruneEffects() []Event {
+	events := []Event{}
+	remaining := []*Effect{}
+	expired := []*Effect{}
+
+	for _, e := range g.Effects {
+		if target, _ := g.findFieldCard(e.TargetInstanceID); target == nil {
+			continue
+		}
+		if e.Duration == DurationWhileSource {
+			if source, _ := g.findFieldCard(e.SourceInstanceID); source == nil {
+				expired = append(expired, e)
+			}
+		}
+		remaining = append(remaining, e)
+	}
+	g.Effects = remaining
+
+	for _, e := range expired {
+		events = append(events, g.expireEffect(e, "source_left"))
+	}
+	if len(expired) == 0 {
+		g.recomputeEffects()
+	}
+	return events
+}
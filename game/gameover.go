@@ -0,0 +1,197 @@
+// gameover.go - Deciding the winner and recording finished games
+package game
+
+import (
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+)
+
+// checkGameOver knocks out players at 0 life or below, and ends the game once one player
+// (or, in team games, one team) is left
+// If every remaining player drops to 0 at once, the side whose turn it is loses:
+// the win goes to the next of them in turn order on another side.
+func (g *Game) checkGameOver() []Event {
+	if g.Winner != "" || !g.Started {
+		return nil
+	}
+
+	alive := g.alivePlayers()
+	dead := []string{}
+	for _, uid := range alive {
+		if g.Players[uid].Life <= 0 {
+			dead = append(dead, uid)
+		}
+	}
+	if len(dead) == 0 {
+		return nil
+	}
+	switch standing := g.sidesLeft(dead); len(standing) {
+	case 0:
+		return g.endGame(g.lastStanding(dead), "life")
+	case 1:
+		return g.endGame(standing[0], "life")
+	}
+
+	events := []Event{}
+	for _, uid := range dead {
+		events = append(events, g.eliminate(uid, "life")...)
+	}
+	return events
+}
+
+// Forfeit takes a player who leaves a started game out of it
+// If only one side is left then it wins; otherwise the game carries on without them.
+func (g *Game) Forfeit(playerUID string) []Event {
+	return g.runLocked(func() []Event { return g.forfeit(playerUID) })
+}
+
+// forfeit takes a player out of the game (caller holds g.mu)
+func (g *Game) forfeit(playerUID string) []Event {
+	p, ok := g.Players[playerUID]
+	if g.Winner != "" || !g.Started || !ok || p.Eliminated {
+		return nil
+	}
+	if standing := g.sidesLeft([]string{playerUID}); len(standing) == 1 {
+		return g.endGame(standing[0], "forfeit")
+	}
+	return g.eliminate(playerUID, "forfeit")
+}
+
+// sidesLeft returns the first player in turn order of each side still standing
+// once the players in out are knocked out
+func (g *Game) sidesLeft(out []string) []string {
+	seen := map[string]bool{}
+	standing := []string{}
+	for _, uid := range g.alivePlayers() {
+		side := g.sideOf(uid)
+		if !slices.Contains(out, uid) && !seen[side] {
+			seen[side] = true
+			standing = append(standing, uid)
+		}
+	}
+	return standing
+}
+
+// lastStanding picks the winner when every remaining player drops to 0 at once:
+// the next of them after the player whose turn it is, on another side
+func (g *Game) lastStanding(dead []string) string {
+	seats := g.seatOrder()
+	start := slices.Index(seats, g.Turn)
+	for n := 1; n <= len(seats); n++ {
+		seat := seats[(start+n+len(seats))%len(seats)]
+		if slices.Contains(dead, seat) && g.sideOf(seat) != g.sideOf(g.Turn) {
+			return seat
+		}
+	}
+	return dead[0]
+}
+
+// opponentOf returns the other player's UID in a two-player game
+func (g *Game) opponentOf(playerUID string) string {
+	for uid := range g.Players {
+		if uid != playerUID {
+			return uid
+		}
+	}
+	return ""
+}
+
+// runLocked runs f under g.mu, then writes the history of a game (or match) that f ended
+// Recording writes profile files, so it happens after unlocking rather than holding up the game.
+func (g *Game) runLocked(f func() []Event) []Event {
+	var rec *MatchRecord
+	var res *MatchResult
+	events := func() []Event {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		events := f()
+		rec, res = g.unrecorded, g.unrecordedResult
+		g.unrecorded, g.unrecordedResult = nil, nil
+		return events
+	}()
+
+	if rec != nil {
+		saved, err := Profiles.RecordMatch(*rec)
+		if err != nil {
+			slog.Error("failed to record match", "game", rec.GameID, "err", err)
+		}
+		if saved.RatingChanges != nil {
+			for _, ev := range events {
+				if ev.Type == "GameOver" {
+					ev.Data["season"] = saved.Season
+					ev.Data["ratingChanges"] = saved.RatingChanges
+				}
+			}
+		}
+	}
+	if res != nil {
+		if err := Profiles.RecordMatchResult(*res); err != nil {
+			slog.Error("failed to record match result", "match", res.MatchID, "err", err)
+		}
+	}
+	return events
+}
+
+// endGame sets the winner and returns the GameOver event
+// The match is recorded by runLocked once the game is unlocked; it adds the rating changes to the event.
+func (g *Game) endGame(winner, reason string) []Event {
+	g.Winner = winner
+	g.EndReason = reason
+	g.EndedAt = time.Now()
+
+	rec := g.matchRecord()
+	g.unrecorded = &rec
+
+	data := map[string]interface{}{
+		"winner": winner,
+		"reason": reason,
+		"turns":  g.TurnNumber,
+		"ranked": g.Ranked,
+	}
+	if g.TeamGame {
+		data["winners"] = g.winners(winner)
+	}
+	events := []Event{{Type: "GameOver", Data: data}}
+	if g.match != nil {
+		data["matchId"] = g.MatchID
+		data["gameNumber"] = g.GameNumber
+		events = append(events, g.matchGameOver()...)
+	}
+	return events
+}
+
+// matchRecord summarizes a finished game for the match history
+func (g *Game) matchRecord() MatchRecord {
+	rec := MatchRecord{
+		GameID:    g.ID,
+		Winner:    g.Winner,
+		Reason:    g.EndReason,
+		Turns:     g.TurnNumber,
+		StartedAt: g.StartedAt,
+		EndedAt:   g.EndedAt,
+		Duration:  int(g.EndedAt.Sub(g.StartedAt).Seconds()),
+		Ranked:    g.Ranked,
+
+		MatchID:    g.MatchID,
+		GameNumber: g.GameNumber,
+	}
+	if g.TeamGame {
+		rec.Winners = g.winners(g.Winner)
+	}
+	if g.Ranked {
+		rec.Season = CurrentSeason()
+	}
+	for uid, p := range g.Players {
+		rec.Players = append(rec.Players, MatchPlayer{
+			UID:      uid,
+			DeckID:   p.DeckID,
+			DeckName: p.DeckName,
+			Leader:   p.LeaderCardID,
+			Life:     p.Life,
+		})
+	}
+	sort.Slice(rec.Players, func(i, j int) bool { return rec.Players[i].UID < rec.Players[j].UID })
+	return rec
+}
@@ -0,0 +1,164 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// testGame returns a started game with the players seated in turn order, the first one to play
+func testGame(uids ...string) *Game {
+	g := &Game{
+		Started:   true,
+		Players:   map[string]*Player{},
+		TurnOrder: uids,
+		Turn:      uids[0],
+		Pool:      testPool,
+		Rules:     DefaultRules,
+	}
+	for _, uid := range uids {
+		g.Players[uid] = &Player{UID: uid, Life: g.Rules.Life, pool: testPool}
+	}
+	return g
+}
+
+func TestCheckGameOver(t *testing.T) {
+	tests := []struct {
+		name       string
+		players    []string
+		teams      map[string]int // Team games only
+		turn       string
+		out        []string // Already eliminated
+		dead       []string // Dropped to 0 life
+		winner     string
+		winners    []string // Team games only
+		eliminated []string // Knocked out by this check
+	}{
+		{
+			name:    "nobody at 0 life",
+			players: []string{"a", "b", "c"},
+		},
+		{
+			name:    "two players",
+			players: []string{"a", "b"},
+			dead:    []string{"b"},
+			winner:  "a",
+		},
+		{
+			name:       "free-for-all carries on without the dead player",
+			players:    []string{"a", "b", "c"},
+			dead:       []string{"b"},
+			eliminated: []string{"b"},
+		},
+		{
+			name:    "free-for-all ends with one player left",
+			players: []string{"a", "b", "c"},
+			dead:    []string{"b", "c"},
+			winner:  "a",
+		},
+		{
+			name:    "free-for-all with a player already out",
+			players: []string{"a", "b", "c"},
+			out:     []string{"c"},
+			dead:    []string{"b"},
+			winner:  "a",
+		},
+		{
+			name:    "everyone at 0: the player after the active one wins",
+			players: []string{"a", "b", "c"},
+			dead:    []string{"a", "b", "c"},
+			winner:  "b",
+		},
+		{
+			name:    "everyone at 0 on a later turn",
+			players: []string{"a", "b", "c"},
+			turn:    "b",
+			dead:    []string{"a", "b", "c"},
+			winner:  "c",
+		},
+		{
+			name:    "last two at 0 skip the eliminated seat",
+			players: []string{"a", "b", "c"},
+			turn:    "c",
+			out:     []string{"a"},
+			dead:    []string{"b", "c"},
+			winner:  "b",
+		},
+		{
+			name:       "teams carry on while a teammate stands",
+			players:    []string{"a", "b", "c", "d"},
+			teams:      map[string]int{"a": 0, "b": 1, "c": 0, "d": 1},
+			dead:       []string{"b"},
+			eliminated: []string{"b"},
+		},
+		{
+			name:    "team wins once the other team is out",
+			players: []string{"a", "b", "c", "d"},
+			teams:   map[string]int{"a": 0, "b": 1, "c": 0, "d": 1},
+			dead:    []string{"b", "d"},
+			winner:  "a",
+			winners: []string{"a", "c"},
+		},
+		{
+			name:    "team wins with only the teammate standing",
+			players: []string{"a", "b", "c", "d"},
+			teams:   map[string]int{"a": 0, "b": 1, "c": 0, "d": 1},
+			dead:    []string{"a", "b", "d"},
+			winner:  "c",
+			winners: []string{"a", "c"},
+		},
+		{
+			name:    "teams all at 0: the other team wins",
+			players: []string{"a", "b", "c", "d"},
+			teams:   map[string]int{"a": 0, "b": 1, "c": 0, "d": 1},
+			dead:    []string{"a", "b", "c", "d"},
+			winner:  "b",
+			winners: []string{"b", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGame(tt.players...)
+			if tt.turn != "" {
+				g.Turn = tt.turn
+			}
+			if tt.teams != nil {
+				g.TeamGame = true
+				g.Teams = tt.teams
+			}
+			for _, uid := range tt.out {
+				g.Players[uid].Eliminated = true
+			}
+			for _, uid := range tt.dead {
+				g.Players[uid].Life = 0
+			}
+
+			events := g.checkGameOver()
+			if g.Winner != tt.winner {
+				t.Errorf("winner %q, want %q", g.Winner, tt.winner)
+			}
+			if tt.winner != "" {
+				if g.unrecorded == nil || g.unrecorded.Winner != tt.winner {
+					t.Errorf("match record %+v", g.unrecorded)
+				}
+				if tt.winners != nil {
+					got := g.winners(g.Winner)
+					slices.Sort(got)
+					if !slices.Equal(got, tt.winners) {
+						t.Errorf("winners %v, want %v", got, tt.winners)
+					}
+				}
+			}
+
+			eliminated := []string{}
+			for _, ev := range events {
+				if ev.Type == "PlayerEliminated" {
+					eliminated = append(eliminated, ev.Data["player"].(string))
+				}
+			}
+			if !slices.Equal(eliminated, tt.eliminated) && len(eliminated)+len(tt.eliminated) > 0 {
+				t.Errorf("eliminated %v, want %v", eliminated, tt.eliminated)
+			}
+		})
+	}
+}
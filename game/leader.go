@@ -0,0 +1,111 @@
+// leader.go - Leader zone: hero powers and returning fallen leaders
+package game
+
+// LeaderTaxPerDeath is the extra colorless mana added to a leader's cost each time it dies
+const LeaderTaxPerDeath = 2
+
+// LeaderTax returns the current recast tax for the player's leader
+func (p *Player) LeaderTax() int {
+	return p.LeaderDeaths * LeaderTaxPerDeath
+}
+
+// LeaderCost returns the mana needed to play the leader, including the recast tax
+func (p *Player) LeaderCost() ManaCost {
+	cost := p.card(p.LeaderCardID).Cost
+	cost.Colorless += p.LeaderTax()
+	return cost
+}
+
+// returnLeader puts a leader that left the field back into its owner's leader zone
+func (g *Game) returnLeader(p *Player, playerUID string, fc *FieldCard, died bool) Event {
+	p.Leader = fc.CardID
+	if died {
+		p.LeaderDeaths++
+	}
+
+	return Event{
+		Type: "LeaderReturned",
+		Data: map[string]interface{}{
+			"player":     playerUID,
+			"cardId":     fc.CardID,
+			"instanceId": fc.InstanceID,
+			"died":       died,
+			"recastTax":  p.LeaderTax(),
+			"leaderCost": p.LeaderCost(),
+		},
+	}
+}
+
+// useLeaderPower handles using the leader's once-per-turn hero power from the leader zone
+func (g *Game) useLeaderPower(a Action) []Event {
+	player := g.Players[a.PlayerUID]
+
+	if player.Leader == 0 {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Leader is not in the leader zone"}}}
+	}
+
+	card := g.Card(player.Leader)
+	if card.HeroPower == nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Leader has no hero power"}}}
+	}
+	power := card.HeroPower
+
+	if player.LeaderPowerUsed {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Hero power already used this turn"}}}
+	}
+
+	if a.TargetID == 0 && a.TargetPlayer == "" && scriptNeedsTarget(power.Script) {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Hero power requires a target"}}}
+	}
+
+	var targetCreature *FieldCard
+	if a.TargetID != 0 {
+		targetCreature, _ = g.findFieldCard(a.TargetID)
+		if targetCreature == nil {
+			return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Target creature not found"}}}
+		}
+	}
+
+	if err := player.payCost(power.Cost, a.Payment, 0); err != nil {
+		return []Event{{Type: "Error", Data: map[string]interface{}{
+			"message":   err.Error(),
+			"required":  power.Cost,
+			"available": player.ManaPool,
+		}}}
+	}
+	player.LeaderPowerUsed = true
+
+	events := []Event{
+		{
+			Type: "LeaderPowerUsed",
+			Data: map[string]interface{}{
+				"player":           a.PlayerUID,
+				"cardId":           player.Leader,
+				"powerName":        power.Name,
+				"targetInstanceId": a.TargetID,
+				"manaPool":         player.ManaPool,
+			},
+		},
+	}
+
+	if power.Script != "" {
+		ctx := &ScriptContext{
+			Game:      g,
+			Card:      nil,
+			Caster:    player,
+			CasterUID: a.PlayerUID,
+			Target:    targetCreature,
+			TargetUID: a.TargetPlayer,
+		}
+		scriptEvents := ExecuteScript(power.Script, ctx)
+		events = append(events, scriptEvents...)
+
+		for uid, p := range g.Players {
+			events = append(events, g.handleDeaths(p, uid)...)
+		}
+
+		events = append(events, g.checkGameOver()...)
+	}
+
+	return events
+}
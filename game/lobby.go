@@ -0,0 +1,196 @@
+// lobby.go - Private lobbies: invite codes, passwords and host controls
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// GameOptions are the settings a host picks when creating a game
+type GameOptions struct {
+	Ranked   bool
+	Private  bool   // Hidden from the game list; joined with the invite code
+	Password string // Shown as locked in the game list; joined with the password
+	BestOf   int    // 3 or 5 plays a best-of-N match (0 or 1 = a single game)
+
+	MaxPlayers int // 3 to MaxSeats for a free-for-all (0 = two players)
+
+	Teams      bool // Two-versus-two: four seats split into two teams
+	SharedLife bool // Teammates share one life total instead of one each
+
+	RuleSet string // Name of the rule set preset (empty = standard rules)
+}
+
+// inviteAlphabet leaves out letters and digits that are easy to mix up (0/O, 1/I/L)
+const inviteAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+const InviteCodeLength = 6
+
+// newInviteCode returns a random invite code not used by another game (caller holds gm.mu)
+func (gm *GameManager) newInviteCode() string {
+	for {
+		buf := make([]byte, InviteCodeLength)
+		rand.Read(buf)
+		for i, b := range buf {
+			buf[i] = inviteAlphabet[int(b)%len(inviteAlphabet)]
+		}
+		code := string(buf)
+		if gm.findByInviteCode(code) == nil {
+			return code
+		}
+	}
+}
+
+// findByInviteCode finds the game using an invite code (caller holds gm.mu)
+func (gm *GameManager) findByInviteCode(code string) *Game {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, g := range gm.games {
+		if g.InviteCode != "" && g.InviteCode == code {
+			return g
+		}
+	}
+	return nil
+}
+
+// FindByInviteCode finds the private game an invite code belongs to
+func (gm *GameManager) FindByInviteCode(code string) *Game {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.findByInviteCode(code)
+}
+
+// hashPassword hashes a lobby password with the game ID as salt
+func hashPassword(gameID, password string) []byte {
+	sum := sha256.Sum256([]byte(gameID + ":" + password))
+	return sum[:]
+}
+
+// IsLocked returns whether joining needs an invite code or password
+func (g *Game) IsLocked() bool {
+	return g.InviteCode != "" || g.passwordHash != nil
+}
+
+// checkSecret checks the invite code or password given when joining
+func (g *Game) checkSecret(secret string) error {
+	if g.InviteCode != "" {
+		code := strings.ToUpper(strings.TrimSpace(secret))
+		if subtle.ConstantTimeCompare([]byte(code), []byte(g.InviteCode)) != 1 {
+			return fmt.Errorf("wrong invite code")
+		}
+		return nil
+	}
+	if g.passwordHash != nil {
+		if subtle.ConstantTimeCompare(hashPassword(g.ID, secret), g.passwordHash) != 1 {
+			return fmt.Errorf("wrong password")
+		}
+	}
+	return nil
+}
+
+// StartLobbyGame lets the host of a locked lobby start the mulligan phase once it's full
+func (gm *GameManager) StartLobbyGame(gameID, hostUID string) (*Game, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if gm.draining {
+		return nil, errDraining
+	}
+	g, ok := gm.games[gameID]
+	if !ok {
+		return nil, fmt.Errorf("game not found")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.checkHost(hostUID); err != nil {
+		return nil, err
+	}
+	if len(g.Players) < 2 {
+		return nil, fmt.Errorf("waiting for an opponent")
+	}
+	if g.TeamGame && len(g.Players) < TeamSeats {
+		return nil, fmt.Errorf("team games need all %d players", TeamSeats)
+	}
+
+	g.assignTeams()
+	g.DrawInitialHands()
+	g.MulliganPhase = true
+	g.MulliganDecisions = make(map[string]bool)
+	return g, nil
+}
+
+// KickPlayer lets the host of a locked lobby remove the player who joined, before the mulligan starts
+func (gm *GameManager) KickPlayer(gameID, hostUID, targetUID string) (*Game, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	g, ok := gm.games[gameID]
+	if !ok {
+		return nil, fmt.Errorf("game not found")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.checkHost(hostUID); err != nil {
+		return nil, err
+	}
+	if targetUID == hostUID {
+		return nil, fmt.Errorf("can't kick yourself")
+	}
+	if _, ok := g.Players[targetUID]; !ok {
+		return nil, fmt.Errorf("%s is not in this game", targetUID)
+	}
+	delete(g.Players, targetUID)
+	g.removeSeat(targetUID)
+	if g.match != nil {
+		g.match.removePlayer(targetUID)
+	}
+	if g.kicked == nil {
+		g.kicked = make(map[string]bool)
+	}
+	g.kicked[targetUID] = true
+	return g, nil
+}
+
+// checkHost checks the game is a locked lobby the player hosts that hasn't started yet (caller holds g.mu)
+func (g *Game) checkHost(hostUID string) error {
+	if g.Host != hostUID {
+		return fmt.Errorf("only the host can do that")
+	}
+	if !g.IsLocked() {
+		return fmt.Errorf("only private lobbies wait for the host")
+	}
+	if g.Started || g.MulliganPhase {
+		return fmt.Errorf("game has already started")
+	}
+	return nil
+}
+
+// LeaveLobby frees the seat of a player who leaves a game that hasn't started
+// A lobby everyone has left is removed.
+func (gm *GameManager) LeaveLobby(gameID, playerUID string) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	g, ok := gm.games[gameID]
+	if !ok {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.Started || g.MulliganPhase {
+		return
+	}
+	delete(g.Players, playerUID)
+	g.removeSeat(playerUID)
+	if g.match != nil {
+		g.match.removePlayer(playerUID)
+	}
+	if len(g.Players) == 0 {
+		delete(gm.games, gameID)
+		delete(gm.matches, g.MatchID)
+	} else if g.Host == playerUID {
+		g.Host = g.TurnOrder[0]
+		g.Turn = g.Host
+	}
+}
@@ -0,0 +1,442 @@
+// mana.go - Mana payment: explicit player-chosen payments and the default chooser
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hybridColors maps the letters used in hybrid symbols to single-color costs
+var hybridColors = map[string]ManaCost{
+	"W": {White: 1},
+	"U": {Blue: 1},
+	"B": {Black: 1},
+	"R": {Red: 1},
+	"G": {Green: 1},
+}
+
+// parseHybrid splits a hybrid symbol like "W/U" into its two color options
+func parseHybrid(symbol string) ([]ManaCost, error) {
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(symbol)), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid hybrid symbol: %s", symbol)
+	}
+	options := []ManaCost{}
+	for _, part := range parts {
+		color, ok := hybridColors[strings.TrimSpace(part)]
+		if !ok {
+			return nil, fmt.Errorf("invalid hybrid color in %s", symbol)
+		}
+		options = append(options, color)
+	}
+	return options, nil
+}
+
+// sameMana compares the fixed amounts of two costs
+func sameMana(a, b ManaCost) bool {
+	return a.White == b.White && a.Blue == b.Blue && a.Black == b.Black &&
+		a.Red == b.Red && a.Green == b.Green && a.Colorless == b.Colorless
+}
+
+// WithX resolves the cost's X symbols for a chosen value, adding it to the colorless cost
+func (m ManaCost) WithX(x int) ManaCost {
+	if x > 0 {
+		m.Colorless += m.X * x
+	}
+	m.X = 0
+	return m
+}
+
+// Alternatives expands hybrid symbols into every fixed cost they allow
+// A cost without hybrid symbols returns just itself. X is dropped (treated as 0).
+func (m ManaCost) Alternatives() []ManaCost {
+	base := m
+	base.X = 0
+	base.Hybrid = nil
+	alts := []ManaCost{base}
+
+	for _, symbol := range m.Hybrid {
+		options, err := parseHybrid(symbol)
+		if err != nil {
+			continue
+		}
+		next := []ManaCost{}
+		for _, alt := range alts {
+			for _, option := range options {
+				c := alt
+				c.Add(option)
+				duplicate := false
+				for _, existing := range next {
+					if sameMana(existing, c) {
+						duplicate = true
+						break
+					}
+				}
+				if !duplicate {
+					next = append(next, c)
+				}
+			}
+		}
+		alts = next
+	}
+	return alts
+}
+
+// Subtract removes an exact payment from the pool
+func (pool *ManaCost) Subtract(payment ManaCost) {
+	pool.White -= payment.White
+	pool.Blue -= payment.Blue
+	pool.Black -= payment.Black
+	pool.Red -= payment.Red
+	pool.Green -= payment.Green
+	pool.Colorless -= payment.Colorless
+}
+
+// Contains checks that every color of the payment is available in the pool
+func (pool ManaCost) Contains(payment ManaCost) bool {
+	return pool.White >= payment.White &&
+		pool.Blue >= payment.Blue &&
+		pool.Black >= payment.Black &&
+		pool.Red >= payment.Red &&
+		pool.Green >= payment.Green &&
+		pool.Colorless >= payment.Colorless
+}
+
+// ValidatePayment checks that an explicit payment comes from the pool and pays the cost exactly
+// Colored costs must be paid with that color; the colorless cost takes the rest
+func (pool ManaCost) ValidatePayment(cost, payment ManaCost) error {
+	if payment.White < 0 || payment.Blue < 0 || payment.Black < 0 ||
+		payment.Red < 0 || payment.Green < 0 || payment.Colorless < 0 {
+		return fmt.Errorf("payment cannot be negative")
+	}
+	if !pool.Contains(payment) {
+		return fmt.Errorf("payment exceeds mana pool")
+	}
+	if payment.White < cost.White || payment.Blue < cost.Blue || payment.Black < cost.Black ||
+		payment.Red < cost.Red || payment.Green < cost.Green {
+		return fmt.Errorf("payment does not cover colored cost")
+	}
+	if payment.Total() != cost.Total() {
+		return fmt.Errorf("payment must total exactly %d mana, got %d", cost.Total(), payment.Total())
+	}
+	return nil
+}
+
+// validateAnyPayment accepts a payment that exactly pays any hybrid choice of the cost
+func (pool ManaCost) validateAnyPayment(cost, payment ManaCost) error {
+	var firstErr error
+	for _, alt := range cost.Alternatives() {
+		err := pool.ValidatePayment(alt, payment)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// payCost pays a cost from the player's pool
+// An explicit payment is validated and used as-is; otherwise the default chooser picks one.
+// castingCardID is the hand card being cast (0 for abilities) so it isn't counted as a future option.
+func (p *Player) payCost(cost ManaCost, payment *ManaCost, castingCardID int) error {
+	if payment != nil {
+		if err := p.ManaPool.validateAnyPayment(cost, *payment); err != nil {
+			return fmt.Errorf("invalid payment: %v", err)
+		}
+		p.ManaPool.Subtract(*payment)
+		return nil
+	}
+
+	if !p.ManaPool.CanAfford(cost) {
+		return fmt.Errorf("Not enough mana in pool")
+	}
+	p.ManaPool.Subtract(p.choosePayment(cost, castingCardID))
+	return nil
+}
+
+// choosePayment picks how to pay the colorless part of a cost so the remaining pool
+// keeps the most cards in hand castable. Ties prefer keeping colors the hand needs,
+// then spending colorless mana first.
+func (p *Player) choosePayment(cost ManaCost, castingCardID int) ManaCost {
+	return p.choosePaymentFrom(p.ManaPool, cost, castingCardID)
+}
+
+// choosePaymentFrom is choosePayment against a given pool instead of the player's current one
+func (p *Player) choosePaymentFrom(pool, cost ManaCost, castingCardID int) ManaCost {
+	options := p.futureCosts(castingCardID)
+
+	best := ManaCost{}
+	bestScore := []int{}
+	found := false
+
+	// Each hybrid choice is its own fixed cost; keep the best payment across all of them
+	for _, alt := range cost.Alternatives() {
+		if !pool.CanAfford(alt) {
+			continue
+		}
+		payment, score := choosePaymentFixed(pool, alt, options)
+		if !found || betterScore(score, bestScore) {
+			best, bestScore, found = payment, score, true
+		}
+	}
+	return best
+}
+
+// choosePaymentFixed finds the best payment of a cost without hybrid symbols
+func choosePaymentFixed(pool, cost ManaCost, options []ManaCost) (ManaCost, []int) {
+	base := ManaCost{
+		White: cost.White,
+		Blue:  cost.Blue,
+		Black: cost.Black,
+		Red:   cost.Red,
+		Green: cost.Green,
+	}
+
+	surplus := pool
+	surplus.Subtract(base)
+
+	best := ManaCost{}
+	bestScore := []int{}
+	found := false
+
+	// Try every split of the colorless cost across the surplus, colorless mana first
+	avail := [6]int{surplus.Colorless, surplus.White, surplus.Blue, surplus.Black, surplus.Red, surplus.Green}
+	var split func(idx, remaining int, extra [6]int)
+	split = func(idx, remaining int, extra [6]int) {
+		if idx == len(extra) {
+			if remaining != 0 {
+				return
+			}
+			payment := base
+			payment.Colorless += extra[0]
+			payment.White += extra[1]
+			payment.Blue += extra[2]
+			payment.Black += extra[3]
+			payment.Red += extra[4]
+			payment.Green += extra[5]
+
+			left := pool
+			left.Subtract(payment)
+			score := scorePool(left, options)
+			if !found || betterScore(score, bestScore) {
+				best, bestScore, found = payment, score, true
+			}
+			return
+		}
+		for n := min(remaining, avail[idx]); n >= 0; n-- {
+			extra[idx] = n
+			split(idx+1, remaining-n, extra)
+		}
+	}
+	split(0, cost.Colorless, [6]int{})
+
+	return best, bestScore
+}
+
+// futureCosts lists the costs of what the player could still cast from hand and leader zone
+func (p *Player) futureCosts(castingCardID int) []ManaCost {
+	costs := []ManaCost{}
+	skipped := false
+	for _, cardID := range p.Hand {
+		if cardID == castingCardID && !skipped {
+			skipped = true
+			continue
+		}
+		card := p.card(cardID)
+		if card.CardType == "Land" {
+			continue
+		}
+		costs = append(costs, card.Cost)
+	}
+	if p.Leader != 0 {
+		costs = append(costs, p.LeaderCost())
+	}
+	return costs
+}
+
+// scorePool rates a leftover pool: castable options, then needed colors kept, then unspent colorless (lower is better)
+func scorePool(left ManaCost, options []ManaCost) []int {
+	castable := 0
+	needed := ManaCost{}
+	for _, cost := range options {
+		if left.CanAfford(cost) {
+			castable++
+		}
+		needed.White = max(needed.White, cost.White)
+		needed.Blue = max(needed.Blue, cost.Blue)
+		needed.Black = max(needed.Black, cost.Black)
+		needed.Red = max(needed.Red, cost.Red)
+		needed.Green = max(needed.Green, cost.Green)
+	}
+
+	kept := min(left.White, needed.White) +
+		min(left.Blue, needed.Blue) +
+		min(left.Black, needed.Black) +
+		min(left.Red, needed.Red) +
+		min(left.Green, needed.Green)
+
+	return []int{castable, kept, -left.Colorless}
+}
+
+// betterScore compares two scores lexicographically
+func betterScore(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
+
+// resolveCost works out the mana a cast from hand pays: the card's cost or the chosen
+// alternative cost, with X filled in. Returns the alternative cost used, if any.
+// a.X is reset to 0 when the chosen cost has no X, so scripts never see a stray value.
+func resolveCost(card Card, a *Action) (ManaCost, *AltCost, error) {
+	if a.X < 0 {
+		return ManaCost{}, nil, fmt.Errorf("X cannot be negative")
+	}
+
+	cost := card.Cost
+	var alt *AltCost
+	if a.AltCost != 0 {
+		if a.AltCost < 1 || a.AltCost > len(card.AltCosts) {
+			return ManaCost{}, nil, fmt.Errorf("Card has no such alternative cost")
+		}
+		alt = &card.AltCosts[a.AltCost-1]
+		cost = alt.Cost
+	}
+	if cost.X == 0 {
+		a.X = 0
+	}
+	return cost.WithX(a.X), alt, nil
+}
+
+// checkAltCost verifies the player can pay the life and discards of an alternative cost
+// castingCardID is the card being cast, which can't also be discarded
+func (p *Player) checkAltCost(alt *AltCost, castingCardID int, discardIDs []int) error {
+	if alt == nil {
+		return nil
+	}
+	if alt.Life > 0 && p.Life <= alt.Life {
+		return fmt.Errorf("Not enough life to pay %d", alt.Life)
+	}
+	if len(discardIDs) != alt.Discard {
+		return fmt.Errorf("Choose exactly %d card(s) to discard", alt.Discard)
+	}
+
+	hand := removeOnce(append([]int{}, p.Hand...), castingCardID)
+	for _, cardID := range discardIDs {
+		before := len(hand)
+		hand = removeOnce(hand, cardID)
+		if len(hand) == before {
+			return fmt.Errorf("Card to discard is not in hand")
+		}
+	}
+	return nil
+}
+
+// payAltCost pays the life and discards of an alternative cost (after checkAltCost)
+func (g *Game) payAltCost(playerUID string, alt *AltCost, cardID int, discardIDs []int) Event {
+	player := g.Players[playerUID]
+	g.changeLife(playerUID, -alt.Life)
+	for _, id := range discardIDs {
+		player.Hand = removeOnce(player.Hand, id)
+		player.Discard = append(player.Discard, id)
+	}
+
+	return Event{
+		Type: "AltCostPaid",
+		Data: map[string]interface{}{
+			"player":    playerUID,
+			"cardId":    cardID,
+			"name":      alt.Name,
+			"life":      alt.Life,
+			"newLife":   player.Life,
+			"discarded": discardIDs,
+		},
+	}
+}
+
+// canCast reports whether a card in hand can be paid for right now, counting untapped
+// lands (for auto-pay) and alternative costs. X is taken as 0.
+func (p *Player) canCast(card Card, cardID int) bool {
+	if _, ok := p.planAutoPay(card.Cost.WithX(0), cardID, false); ok {
+		return true
+	}
+	for _, alt := range card.AltCosts {
+		if alt.Life > 0 && p.Life <= alt.Life {
+			continue
+		}
+		if len(p.Hand)-1 < alt.Discard {
+			continue
+		}
+		if _, ok := p.planAutoPay(alt.Cost.WithX(0), cardID, false); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// removeOnce removes the first occurrence of id from cards
+func removeOnce(cards []int, id int) []int {
+	for i, c := range cards {
+		if c == id {
+			return append(cards[:i], cards[i+1:]...)
+		}
+	}
+	return cards
+}
+
+// manaColorNames maps the names and letters a player can send when picking a land's mana
+var manaColorNames = map[string]string{
+	"white": "White", "w": "White",
+	"blue": "Blue", "u": "Blue",
+	"black": "Black", "b": "Black",
+	"red": "Red", "r": "Red",
+	"green": "Green", "g": "Green",
+	"colorless": "Colorless", "c": "Colorless",
+}
+
+// amountOf returns how much of a named color the mana contains
+func (m ManaCost) amountOf(color string) int {
+	switch color {
+	case "White":
+		return m.White
+	case "Blue":
+		return m.Blue
+	case "Black":
+		return m.Black
+	case "Red":
+		return m.Red
+	case "Green":
+		return m.Green
+	case "Colorless":
+		return m.Colorless
+	}
+	return 0
+}
+
+// chooseManaOption picks which of a land's mana options to produce
+// A land with a single option ignores color; otherwise color must match one of the options.
+func chooseManaOption(card Card, color string) (ManaCost, error) {
+	options := card.ManaOptions()
+	if len(options) == 0 {
+		return ManaCost{}, nil
+	}
+	if len(options) == 1 {
+		return options[0], nil
+	}
+
+	name, ok := manaColorNames[strings.ToLower(strings.TrimSpace(color))]
+	if !ok {
+		return ManaCost{}, fmt.Errorf("Choose a color for %s", card.Name)
+	}
+	for _, option := range options {
+		if option.amountOf(name) > 0 {
+			return option, nil
+		}
+	}
+	return ManaCost{}, fmt.Errorf("%s can't produce %s mana", card.Name, name)
+}
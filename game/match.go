@@ -0,0 +1,400 @@
+// match.go - Best-of-N matches: several games between the same players, with sideboarding between games
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Match phases
+const (
+	MatchPlaying      = "playing"      // A game is in progress
+	MatchSideboarding = "sideboarding" // Between games: sideboarding and choosing who goes first
+	MatchOver         = "over"
+)
+
+// MatchLengths lists the match lengths start_game accepts besides a single game
+var MatchLengths = map[int]bool{3: true, 5: true}
+
+// Match wraps the games of a best-of-N between two players
+type Match struct {
+	mu sync.Mutex
+
+	ID      string
+	BestOf  int
+	Ranked  bool
+	Players []string        // In join order, host first
+	Decks   map[string]Deck // Registered decks, including sideboards
+	Current map[string]Deck // Decks for the next game, after sideboarding
+	Wins    map[string]int  // Games won by each player
+	GameIDs []string        // Games played so far, in order
+	Phase   string          // MatchPlaying, MatchSideboarding or MatchOver
+	Winner  string          // Set when the match is over
+	Chooser string          // Loser of the last game, who picks who goes first
+	First   string          // Who goes first next game (once chosen)
+	Ready   map[string]bool // Players done sideboarding
+	Started time.Time
+
+	pool  *CardPool // Card data the match's decks were checked against
+	rules RuleSet   // Rules every game of the match is played with
+}
+
+// MatchResult is a finished match in the history
+type MatchResult struct {
+	MatchID string         `json:"matchId"`
+	BestOf  int            `json:"bestOf"`
+	Players []string       `json:"players"`
+	Wins    map[string]int `json:"wins"`
+	Winner  string         `json:"winner"`
+	Reason  string         `json:"reason"` // "wins" or "forfeit"
+	GameIDs []string       `json:"gameIds"`
+	Ranked  bool           `json:"ranked,omitempty"`
+	Started time.Time      `json:"startedAt"`
+	Ended   time.Time      `json:"endedAt"`
+}
+
+// winsNeeded returns how many game wins take the match
+func (m *Match) winsNeeded() int {
+	return m.BestOf/2 + 1
+}
+
+// newMatch starts a match around the first game of a lobby (caller holds gm.mu)
+func (gm *GameManager) newMatch(g *Game, bestOf int, hostDeck Deck) *Match {
+	if gm.matches == nil {
+		gm.matches = make(map[string]*Match)
+	}
+	m := &Match{
+		ID:      fmt.Sprintf("match_%d", gm.nextID),
+		BestOf:  bestOf,
+		Ranked:  g.Ranked,
+		Players: []string{g.Host},
+		Decks:   map[string]Deck{g.Host: hostDeck},
+		Current: map[string]Deck{g.Host: hostDeck},
+		Wins:    map[string]int{g.Host: 0},
+		GameIDs: []string{g.ID},
+		Phase:   MatchPlaying,
+		Started: time.Now(),
+		pool:    g.Pool,
+		rules:   g.Rules,
+	}
+	gm.matches[m.ID] = m
+	g.MatchID = m.ID
+	g.GameNumber = 1
+	g.match = m
+	return m
+}
+
+// addPlayer registers the opponent who joined the match's first game
+func (m *Match) addPlayer(uid string, deck Deck) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Players = append(m.Players, uid)
+	m.Decks[uid] = deck
+	m.Current[uid] = deck
+	m.Wins[uid] = 0
+}
+
+// GetMatch returns a match by ID
+func (gm *GameManager) GetMatch(matchID string) *Match {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	return gm.matches[matchID]
+}
+
+// scoreEvent describes the match score
+func (m *Match) scoreEvent(eventType string) Event {
+	wins := map[string]int{}
+	for uid, n := range m.Wins {
+		wins[uid] = n
+	}
+	data := map[string]interface{}{
+		"matchId":    m.ID,
+		"bestOf":     m.BestOf,
+		"wins":       wins,
+		"gameNumber": len(m.GameIDs),
+		"phase":      m.Phase,
+	}
+	switch m.Phase {
+	case MatchSideboarding:
+		data["chooser"] = m.Chooser
+		data["firstPlayer"] = m.First
+		ready := map[string]bool{}
+		for uid, r := range m.Ready {
+			ready[uid] = r
+		}
+		data["ready"] = ready
+	case MatchOver:
+		data["winner"] = m.Winner
+	}
+	return Event{Type: eventType, Data: data}
+}
+
+// matchGameOver updates the match after one of its games ends
+// A forfeited game forfeits the whole match.
+func (g *Game) matchGameOver() []Event {
+	m := g.match
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Phase != MatchPlaying {
+		return nil
+	}
+	m.Wins[g.Winner]++
+
+	if g.EndReason == "forfeit" || m.Wins[g.Winner] >= m.winsNeeded() {
+		reason := "wins"
+		if g.EndReason == "forfeit" {
+			reason = "forfeit"
+		}
+		ev, result := m.finish(g.Winner, reason)
+		g.unrecordedResult = &result
+		return []Event{ev}
+	}
+
+	m.Phase = MatchSideboarding
+	m.Chooser = g.opponentOf(g.Winner)
+	m.First = ""
+	m.Ready = make(map[string]bool)
+	return []Event{m.scoreEvent("SideboardPhase")}
+}
+
+// finish ends the match, returning the MatchOver event and the result to record (caller holds m.mu)
+func (m *Match) finish(winner, reason string) (Event, MatchResult) {
+	m.Phase = MatchOver
+	m.Winner = winner
+
+	players := append([]string{}, m.Players...)
+	sort.Strings(players)
+	result := MatchResult{
+		MatchID: m.ID,
+		BestOf:  m.BestOf,
+		Players: players,
+		Wins:    map[string]int{},
+		Winner:  winner,
+		Reason:  reason,
+		GameIDs: m.GameIDs,
+		Ranked:  m.Ranked,
+		Started: m.Started,
+		Ended:   time.Now(),
+	}
+	for uid, n := range m.Wins {
+		result.Wins[uid] = n
+	}
+	ev := m.scoreEvent("MatchOver")
+	ev.Data["reason"] = reason
+	return ev, result
+}
+
+// matchOngoing reports whether the game is part of a match that isn't over (caller holds g.mu)
+func (g *Game) matchOngoing() bool {
+	if g.match == nil {
+		return false
+	}
+	g.match.mu.Lock()
+	defer g.match.mu.Unlock()
+	return g.match.Phase != MatchOver
+}
+
+// ForfeitMatch concedes a match between games, when the player leaves during sideboarding
+func (g *Game) ForfeitMatch(playerUID string) []Event {
+	return g.runLocked(func() []Event { return g.forfeitMatch(playerUID) })
+}
+
+// forfeitMatch concedes the match (caller holds g.mu)
+func (g *Game) forfeitMatch(playerUID string) []Event {
+	m := g.match
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Phase != MatchSideboarding {
+		return nil
+	}
+	ev, result := m.finish(g.opponentOf(playerUID), "forfeit")
+	g.unrecordedResult = &result
+	return []Event{ev}
+}
+
+// SubmitSideboard sets the deck a player uses for the next game of a match
+// The new deck must use exactly the cards of the registered deck (main deck, vault
+// and sideboard) with the same leader, and still be legal. A nil deck keeps the
+// current one. Returns the next game if this started it.
+func (gm *GameManager) SubmitSideboard(matchID, playerUID string, deck *Deck) (*Game, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	m, ok := gm.matches[matchID]
+	if !ok {
+		return nil, fmt.Errorf("match not found")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkSideboarding(playerUID); err != nil {
+		return nil, err
+	}
+
+	if deck != nil {
+		next, err := m.swapDeck(playerUID, *deck)
+		if err != nil {
+			return nil, err
+		}
+		m.Current[playerUID] = next
+	}
+	m.Ready[playerUID] = true
+
+	return gm.startNextMatchGame(m), nil
+}
+
+// ChooseFirst lets the loser of the last game pick who goes first in the next one
+func (gm *GameManager) ChooseFirst(matchID, playerUID, firstUID string) (*Game, error) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	m, ok := gm.matches[matchID]
+	if !ok {
+		return nil, fmt.Errorf("match not found")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkSideboarding(playerUID); err != nil {
+		return nil, err
+	}
+	if playerUID != m.Chooser {
+		return nil, fmt.Errorf("%s lost the last game and chooses who goes first", m.Chooser)
+	}
+	if _, ok := m.Wins[firstUID]; !ok {
+		return nil, fmt.Errorf("%s is not in this match", firstUID)
+	}
+	m.First = firstUID
+
+	return gm.startNextMatchGame(m), nil
+}
+
+// checkSideboarding checks the player is in the match and it's between games (caller holds m.mu)
+func (m *Match) checkSideboarding(playerUID string) error {
+	if _, ok := m.Wins[playerUID]; !ok {
+		return fmt.Errorf("you are not in this match")
+	}
+	if m.Phase != MatchSideboarding {
+		return fmt.Errorf("not between games")
+	}
+	return nil
+}
+
+// swapDeck checks a sideboarded deck against the player's registered deck (caller holds m.mu)
+func (m *Match) swapDeck(playerUID string, deck Deck) (Deck, error) {
+	base := m.Decks[playerUID]
+	if deck.Leader != base.Leader {
+		return Deck{}, fmt.Errorf("the leader can't be changed between games")
+	}
+
+	// Whatever isn't in the main deck or vault goes back to the sideboard
+	available := map[int]int{}
+	for _, ids := range [][]int{base.MainDeck, base.Vault, base.Sideboard} {
+		for _, id := range ids {
+			available[id]++
+		}
+	}
+	for _, ids := range [][]int{deck.MainDeck, deck.Vault} {
+		for _, id := range ids {
+			if available[id] == 0 {
+				return Deck{}, fmt.Errorf("card %d is not in your deck or sideboard (or too many copies)", id)
+			}
+			available[id]--
+		}
+	}
+	next := base
+	next.MainDeck = append([]int{}, deck.MainDeck...)
+	next.Vault = append([]int{}, deck.Vault...)
+	next.Sideboard = []int{}
+	all := append(append(append([]int{}, base.MainDeck...), base.Vault...), base.Sideboard...)
+	for _, id := range uniqueIDs(all) {
+		for n := available[id]; n > 0; n-- {
+			next.Sideboard = append(next.Sideboard, id)
+		}
+	}
+
+	if err := m.rules.ValidateDeck(next, m.pool); err != nil {
+		return Deck{}, err
+	}
+	return next, nil
+}
+
+// startNextMatchGame starts the next game once both players are ready and the first player is chosen
+// (caller holds gm.mu and m.mu)
+func (gm *GameManager) startNextMatchGame(m *Match) *Game {
+	if m.First == "" {
+		return nil
+	}
+	for _, uid := range m.Players {
+		if !m.Ready[uid] {
+			return nil
+		}
+	}
+
+	players := map[string]*Player{}
+	for _, uid := range m.Players {
+		players[uid] = NewPlayer(uid, m.Current[uid], m.pool, &m.rules)
+	}
+
+	// The finished game is only kept until the next one starts
+	delete(gm.games, m.GameIDs[len(m.GameIDs)-1])
+
+	g := &Game{
+		ID:                fmt.Sprintf("game_%d", gm.nextID),
+		Players:           players,
+		TurnOrder:         append([]string{}, m.Players...),
+		Turn:              m.First,
+		NextInstanceID:    1,
+		Pool:              m.pool,
+		Rules:             m.rules,
+		Ranked:            m.Ranked,
+		Host:              m.Players[0],
+		MatchID:           m.ID,
+		GameNumber:        len(m.GameIDs) + 1,
+		MulliganPhase:     true,
+		MulliganDecisions: make(map[string]bool),
+		match:             m,
+	}
+	gm.nextID++
+	g.DrawInitialHands()
+	gm.games[g.ID] = g
+
+	m.GameIDs = append(m.GameIDs, g.ID)
+	m.Phase = MatchPlaying
+	m.Chooser, m.First, m.Ready = "", "", nil
+	return g
+}
+
+// MatchDeck returns the deck and sideboard a player has for the next game of a match
+func (m *Match) MatchDeck(playerUID string) (Deck, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deck, ok := m.Current[playerUID]
+	return deck, ok
+}
+
+// Score returns a snapshot event of the match score
+func (m *Match) Score(eventType string) Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.scoreEvent(eventType)
+}
+
+// removePlayer unregisters a player kicked from the match's first game before it started
+func (m *Match) removePlayer(uid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, p := range m.Players {
+		if p == uid {
+			m.Players = append(m.Players[:i], m.Players[i+1:]...)
+			break
+		}
+	}
+	delete(m.Decks, uid)
+	delete(m.Current, uid)
+	delete(m.Wins, uid)
+}
@@ -0,0 +1,239 @@
+// matchmaking.go - Matchmaking queue: pairs queued players by rating on a background goroutine
+package game
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	MatchmakingInterval = 2 * time.Second  // How often the queue is paired
+	QueueBaseRange      = 100              // Rating difference accepted straight away
+	QueueRangeGrowth    = 50               // Extra rating difference accepted per QueueRangeStep waited
+	QueueRangeStep      = 10 * time.Second // How often the search range widens
+	QueueMaxRange       = 1000             // Widest search range
+)
+
+// QueueFormats lists the formats players can queue for
+var QueueFormats = map[string]bool{
+	"casual": true,
+	"ranked": true,
+}
+
+// QueueEntry is one player waiting for a match
+type QueueEntry struct {
+	PlayerUID string    `json:"playerUid"`
+	DeckID    int       `json:"deckId"`
+	Format    string    `json:"format"`
+	Rating    int       `json:"rating"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// SearchRange returns the rating difference the entry accepts after waiting until now
+func (e *QueueEntry) SearchRange(now time.Time) int {
+	steps := int(now.Sub(e.JoinedAt) / QueueRangeStep)
+	return min(QueueBaseRange+steps*QueueRangeGrowth, QueueMaxRange)
+}
+
+// QueueStatus is sent to a queued player each time the queue is paired
+type QueueStatus struct {
+	Format      string `json:"format"`
+	Position    int    `json:"position"` // 1-based position among players queued for the same format
+	QueueSize   int    `json:"queueSize"`
+	WaitSeconds int    `json:"waitSeconds"`
+	SearchRange int    `json:"searchRange"`
+	Rating      int    `json:"rating"`
+}
+
+// Matchmaker holds the queue; the server sets the callbacks to notify players
+type Matchmaker struct {
+	mu    sync.Mutex
+	queue []*QueueEntry
+
+	OnMatch  func(g *Game, format string)               // A game was created for paired players
+	OnStatus func(playerUID string, status QueueStatus) // Periodic update for a queued player
+	OnFailed func(playerUID string, err error)          // A player was dropped from the queue
+}
+
+var Matchmaking = &Matchmaker{}
+
+// Enqueue adds a player to the queue for a format with a deck they can use
+func (mm *Matchmaker) Enqueue(playerUID string, deckID int, format string) (QueueStatus, error) {
+	if playerUID == "" {
+		return QueueStatus{}, fmt.Errorf("missing player UID")
+	}
+	if format == "" {
+		format = "casual"
+	}
+	if !QueueFormats[format] {
+		return QueueStatus{}, fmt.Errorf("unknown format %q", format)
+	}
+	if Manager.Draining() {
+		return QueueStatus{}, errDraining
+	}
+	if g := Manager.PlayerGame(playerUID); g != nil {
+		return QueueStatus{}, fmt.Errorf("already in game %s", g.ID)
+	}
+	if _, err := findDeck(CurrentPool(), &DefaultRules, playerUID, deckID); err != nil {
+		return QueueStatus{}, err
+	}
+	rating, _ := Profiles.Rating(CurrentSeason(), playerUID)
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for _, e := range mm.queue {
+		if e.PlayerUID == playerUID {
+			return QueueStatus{}, fmt.Errorf("already in the %s queue", e.Format)
+		}
+	}
+	entry := &QueueEntry{
+		PlayerUID: playerUID,
+		DeckID:    deckID,
+		Format:    format,
+		Rating:    rating.Rating,
+		JoinedAt:  time.Now(),
+	}
+	mm.queue = append(mm.queue, entry)
+	return mm.status(entry, entry.JoinedAt), nil
+}
+
+// Cancel removes a player from the queue, returning whether they were queued
+func (mm *Matchmaker) Cancel(playerUID string) bool {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	for i, e := range mm.queue {
+		if e.PlayerUID == playerUID {
+			mm.queue = append(mm.queue[:i], mm.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// status describes an entry's place in the queue (caller holds mm.mu)
+func (mm *Matchmaker) status(entry *QueueEntry, now time.Time) QueueStatus {
+	st := QueueStatus{
+		Format:      entry.Format,
+		WaitSeconds: int(now.Sub(entry.JoinedAt).Seconds()),
+		SearchRange: entry.SearchRange(now),
+		Rating:      entry.Rating,
+	}
+	for _, e := range mm.queue {
+		if e.Format != entry.Format {
+			continue
+		}
+		st.QueueSize++
+		if e == entry {
+			st.Position = st.QueueSize
+		}
+	}
+	return st
+}
+
+// StartMatchmaking starts the background goroutine that pairs the queue, which runs until ctx is done
+func (mm *Matchmaker) StartMatchmaking(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(MatchmakingInterval)
+		defer ticker.Stop()
+		slog.Info("matchmaking routine started")
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("matchmaking routine stopped")
+				return
+			case now := <-ticker.C:
+				mm.runPairing(now)
+			}
+		}
+	}()
+}
+
+// runPairing pairs the queue, creates games for the pairs and notifies everyone
+// Callbacks run after the queue lock is released.
+func (mm *Matchmaker) runPairing(now time.Time) {
+	mm.mu.Lock()
+	pairs := mm.pair(now)
+	statuses := map[string]QueueStatus{}
+	for _, e := range mm.queue {
+		statuses[e.PlayerUID] = mm.status(e, now)
+	}
+	mm.mu.Unlock()
+
+	for _, pair := range pairs {
+		format := pair[0].Format
+		g, err := Manager.CreateMatch(pair, format == "ranked")
+		if err != nil {
+			// One of the decks stopped being legal: drop both and let them requeue
+			slog.Warn("matchmaking failed", "err", err)
+			for _, e := range pair {
+				if mm.OnFailed != nil {
+					mm.OnFailed(e.PlayerUID, err)
+				}
+			}
+			continue
+		}
+		slog.Info("matchmaking paired players", "players", []string{pair[0].PlayerUID, pair[1].PlayerUID}, "format", format, "game", g.ID)
+		if mm.OnMatch != nil {
+			mm.OnMatch(g, format)
+		}
+	}
+
+	if mm.OnStatus != nil {
+		for uid, st := range statuses {
+			mm.OnStatus(uid, st)
+		}
+	}
+}
+
+// pair removes and returns pairs of compatible players (caller holds mm.mu)
+// The longest-waiting players are paired first, each with the closest-rated
+// player both of them accept.
+func (mm *Matchmaker) pair(now time.Time) [][]*QueueEntry {
+	sort.SliceStable(mm.queue, func(i, j int) bool {
+		return mm.queue[i].JoinedAt.Before(mm.queue[j].JoinedAt)
+	})
+
+	matched := map[*QueueEntry]bool{}
+	pairs := [][]*QueueEntry{}
+	for i, a := range mm.queue {
+		if matched[a] {
+			continue
+		}
+		var best *QueueEntry
+		bestDiff := math.MaxInt
+		for _, b := range mm.queue[i+1:] {
+			if matched[b] || b.Format != a.Format {
+				continue
+			}
+			diff := a.Rating - b.Rating
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > a.SearchRange(now) || diff > b.SearchRange(now) {
+				continue
+			}
+			if diff < bestDiff {
+				best, bestDiff = b, diff
+			}
+		}
+		if best != nil {
+			matched[a], matched[best] = true, true
+			pairs = append(pairs, []*QueueEntry{a, best})
+		}
+	}
+
+	remaining := []*QueueEntry{}
+	for _, e := range mm.queue {
+		if !matched[e] {
+			remaining = append(remaining, e)
+		}
+	}
+	mm.queue = remaining
+	return pairs
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSearchRange(t *testing.T) {
+	joined := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		waited time.Duration
+		want   int
+	}{
+		{0, QueueBaseRange},
+		{QueueRangeStep - time.Second, QueueBaseRange},
+		{QueueRangeStep, QueueBaseRange + QueueRangeGrowth},
+		{3 * QueueRangeStep, QueueBaseRange + 3*QueueRangeGrowth},
+		{time.Hour, QueueMaxRange},
+	}
+	for _, tt := range tests {
+		e := &QueueEntry{JoinedAt: joined}
+		if got := e.SearchRange(joined.Add(tt.waited)); got != tt.want {
+			t.Errorf("after %v: range %d, want %d", tt.waited, got, tt.want)
+		}
+	}
+}
+
+func TestPair(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	type queued struct {
+		uid    string
+		rating int
+		waited time.Duration
+		format string
+	}
+	tests := []struct {
+		name  string
+		queue []queued
+		pairs [][2]string // Longest-waiting player first
+		left  int
+	}{
+		{
+			name:  "close ratings pair straight away",
+			queue: []queued{{"a", 1500, 0, "casual"}, {"b", 1550, 0, "casual"}},
+			pairs: [][2]string{{"a", "b"}},
+		},
+		{
+			name:  "distant ratings wait",
+			queue: []queued{{"a", 1500, 0, "casual"}, {"b", 1700, 0, "casual"}},
+			left:  2,
+		},
+		{
+			name:  "the range widens while both wait",
+			queue: []queued{{"a", 1500, 2 * QueueRangeStep, "casual"}, {"b", 1700, 2 * QueueRangeStep, "casual"}},
+			pairs: [][2]string{{"a", "b"}},
+		},
+		{
+			name:  "both players must accept the difference",
+			queue: []queued{{"a", 1500, 10 * QueueRangeStep, "casual"}, {"b", 1700, 0, "casual"}},
+			left:  2,
+		},
+		{
+			name:  "the widest range still has a limit",
+			queue: []queued{{"a", 1500, time.Hour, "casual"}, {"b", 1500 + QueueMaxRange + 1, time.Hour, "casual"}},
+			left:  2,
+		},
+		{
+			name:  "formats are never mixed",
+			queue: []queued{{"a", 1500, 0, "casual"}, {"b", 1500, 0, "ranked"}},
+			left:  2,
+		},
+		{
+			name: "the longest-waiting player gets the closest rating",
+			queue: []queued{
+				{"b", 1580, time.Second, "ranked"},
+				{"a", 1500, time.Minute, "ranked"},
+				{"c", 1520, 0, "ranked"},
+			},
+			pairs: [][2]string{{"a", "c"}},
+			left:  1,
+		},
+		{
+			name: "several pairs in one pass",
+			queue: []queued{
+				{"a", 1500, 3 * time.Second, "casual"},
+				{"b", 1900, 2 * time.Second, "casual"},
+				{"c", 1510, time.Second, "casual"},
+				{"d", 1950, 0, "casual"},
+			},
+			pairs: [][2]string{{"a", "c"}, {"b", "d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mm := &Matchmaker{}
+			for _, q := range tt.queue {
+				mm.queue = append(mm.queue, &QueueEntry{PlayerUID: q.uid, Rating: q.rating, Format: q.format, JoinedAt: now.Add(-q.waited)})
+			}
+			pairs := mm.pair(now)
+			if len(pairs) != len(tt.pairs) {
+				t.Fatalf("%d pairs, want %d", len(pairs), len(tt.pairs))
+			}
+			for i, p := range pairs {
+				if got := [2]string{p[0].PlayerUID, p[1].PlayerUID}; got != tt.pairs[i] {
+					t.Errorf("pair %d is %v, want %v", i, got, tt.pairs[i])
+				}
+			}
+			if len(mm.queue) != tt.left {
+				t.Errorf("%d left in the queue, want %d", len(mm.queue), tt.left)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 // mulligan.go - Mulligan phase logic
 package game
 
+import "time"
+
 // keepHand - player keeps their current hand during mulligan phase
 func (g *Game) keepHand(a Action) []Event {
 	if g.MulliganDecisions[a.PlayerUID] {
@@ -26,11 +28,15 @@ func (g *Game) takeMulligan(a Action) []Event {
 		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Already made mulligan decision"}}}
 	}
 
+	if g.Rules.Mulligan == MulliganNone {
+		return []Event{{Type: "Error", Data: map[string]interface{}{"message": "Mulligans are off in this rule set"}}}
+	}
+
 	player := g.Players[a.PlayerUID]
 
 	// Separate hand into lands (vault) and non-lands (main deck)
 	for _, cardID := range player.Hand {
-		card := CardDB[cardID]
+		card := g.Card(cardID)
 		if card.CardType == "Land" {
 			player.VaultPile = append(player.VaultPile, cardID)
 		} else {
@@ -43,9 +49,9 @@ func (g *Game) takeMulligan(a Action) []Event {
 	player.DrawPile = ShuffleDeck(player.DrawPile)
 	player.VaultPile = ShuffleDeck(player.VaultPile)
 
-	// Draw new hand
-	player.DrawCards(InitialMainDeckDraw)
-	player.DrawFromVault(InitialVaultDraw)
+	// Draw new hand (one card fewer under the minus_one policy)
+	player.DrawCards(g.Rules.mulliganDraw())
+	player.DrawFromVault(g.Rules.InitialVaultDraw)
 
 	g.MulliganDecisions[a.PlayerUID] = true
 
@@ -65,7 +71,7 @@ func (g *Game) takeMulligan(a Action) []Event {
 	return events
 }
 
-// checkMulliganComplete checks if both players decided and starts the game
+// checkMulliganComplete checks if every player decided and starts the game
 func (g *Game) checkMulliganComplete() []Event {
 	for uid := range g.Players {
 		if !g.MulliganDecisions[uid] {
@@ -73,16 +79,20 @@ func (g *Game) checkMulliganComplete() []Event {
 		}
 	}
 
-	// Both decided - start the game
+	// Everyone decided - start the game
 	g.MulliganPhase = false
 	g.Started = true
 	g.DrawPhase = true
+	g.TurnNumber = 1
+	g.StartedAt = time.Now()
+	g.TurnStartedAt = g.StartedAt
 
 	playersInfo := make(map[string]interface{})
 	for uid, player := range g.Players {
 		playersInfo[uid] = map[string]interface{}{
 			"hand":        player.Hand,
 			"leader":      player.Leader,
+			"life":        player.Life,
 			"deckSize":    len(player.DrawPile),
 			"vaultSize":   len(player.VaultPile),
 			"discardSize": len(player.Discard),
@@ -98,6 +108,10 @@ func (g *Game) checkMulliganComplete() []Event {
 				"gameId":      g.ID,
 				"players":     playersInfo,
 				"currentTurn": g.Turn,
+				"turnOrder":   g.TurnOrder,
+				"teams":       g.Teams,
+				"sharedLife":  g.SharedLife,
+				"rules":       g.Rules,
 			},
 		},
 		{
@@ -0,0 +1,125 @@
+// packs.go - Booster packs for limited formats: card rarities and pack collation
+package game
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Card rarities, most common first
+const (
+	RarityCommon   = "Common"
+	RarityUncommon = "Uncommon"
+	RarityRare     = "Rare"
+	RarityMythic   = "Mythic"
+)
+
+// KnownRarities lists the accepted Rarity settings on cards
+var KnownRarities = map[string]bool{
+	RarityCommon:   true,
+	RarityUncommon: true,
+	RarityRare:     true,
+	RarityMythic:   true,
+}
+
+// rarityOrder ranks rarities from most to least common
+var rarityOrder = []string{RarityCommon, RarityUncommon, RarityRare, RarityMythic}
+
+// PackSlot is one group of cards of the same rarity in a pack
+type PackSlot struct {
+	Rarity string
+	Count  int
+}
+
+// PackSlots is how a booster pack is collated: one rare, three uncommons and eight commons
+var PackSlots = []PackSlot{
+	{RarityRare, 1},
+	{RarityUncommon, 3},
+	{RarityCommon, 8},
+}
+
+// MythicChance is the 1-in-N chance that a pack's rare is upgraded to a mythic
+const MythicChance = 8
+
+// PackRarity returns the card's rarity
+// Cards without a Rarity get one from their mana value: 5+ is rare, 3-4 uncommon, the rest common.
+func (c Card) PackRarity() string {
+	if c.Rarity != "" {
+		return c.Rarity
+	}
+	switch total := c.Cost.Total(); {
+	case total >= 5:
+		return RarityRare
+	case total >= 3:
+		return RarityUncommon
+	}
+	return RarityCommon
+}
+
+// inPacks reports whether a card can be opened in a pack
+// Lands aren't: limited decks get their vault added automatically.
+func (c Card) inPacks() bool {
+	return c.CardType != "Land"
+}
+
+// packCards groups the cards that can be opened in packs by rarity, in ID order
+func packCards(pool *CardPool) map[string][]int {
+	byRarity := map[string][]int{}
+	for id, card := range pool.Cards {
+		if card.inPacks() {
+			rarity := card.PackRarity()
+			byRarity[rarity] = append(byRarity[rarity], id)
+		}
+	}
+	for _, ids := range byRarity {
+		sort.Ints(ids)
+	}
+	return byRarity
+}
+
+// OpenPack collates one booster pack from a card pool, with no card twice in the same pack
+// A slot whose rarity has run out takes the next more common rarity instead.
+func OpenPack(pool *CardPool) []int {
+	byRarity := packCards(pool)
+	inPack := map[int]bool{}
+	pack := []int{}
+	for _, slot := range PackSlots {
+		rarity := slot.Rarity
+		if rarity == RarityRare && len(byRarity[RarityMythic]) > 0 && rand.Intn(MythicChance) == 0 {
+			rarity = RarityMythic
+		}
+		for n := 0; n < slot.Count; n++ {
+			if id, ok := pickForSlot(byRarity, rarity, inPack); ok {
+				inPack[id] = true
+				pack = append(pack, id)
+			}
+		}
+	}
+	return pack
+}
+
+// pickForSlot picks a random card of the rarity not already in the pack, falling back to more common ones
+func pickForSlot(byRarity map[string][]int, rarity string, inPack map[int]bool) (int, bool) {
+	for i := rarityRank(rarity); i >= 0; i-- {
+		choices := []int{}
+		for _, id := range byRarity[rarityOrder[i]] {
+			if !inPack[id] {
+				choices = append(choices, id)
+			}
+		}
+		if len(choices) > 0 {
+			return choices[rand.Intn(len(choices))], true
+		}
+	}
+	return 0, false
+}
+
+// rarityRank returns a rarity's position in rarityOrder (0 = common)
+func rarityRank(rarity string) int {
+	for i, r := range rarityOrder {
+		if r == rarity {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+// pool.go - Versioned card pool: games keep the card data they started with across reloads
+package game
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CardPool is one version of the card and deck data
+// A pool is never modified after it's published; reloading creates a new one.
+type CardPool struct {
+	Version int
+	Cards   map[int]Card
+	Decks   map[int]Deck
+}
+
+// Card looks up a card definition in this pool
+func (cp *CardPool) Card(id int) Card {
+	return cp.Cards[id]
+}
+
+var (
+	poolMu      sync.RWMutex
+	currentPool *CardPool
+)
+
+// CurrentPool returns the pool new games are created with
+// The first call builds version 1 from the data loaded by LoadCards and LoadDecks.
+func CurrentPool() *CardPool {
+	poolMu.RLock()
+	cp := currentPool
+	poolMu.RUnlock()
+	if cp != nil {
+		return cp
+	}
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if currentPool == nil {
+		currentPool = &CardPool{Version: 1, Cards: CardDB, Decks: DeckDB}
+	}
+	return currentPool
+}
+
+// ReloadData reads and validates new card and deck files, then swaps them in as the current pool
+// Nothing changes if the files can't be read or have problems. Games already running keep their pool.
+func ReloadData(cardsPath, decksPath string) (*CardPool, []DataProblem, error) {
+	cards, err := ReadCards(cardsPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", cardsPath, err)
+	}
+	decks, err := ReadDecks(decksPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", decksPath, err)
+	}
+	if problems := ValidateData(cardsPath, cards, decksPath, decks); len(problems) > 0 {
+		return nil, problems, fmt.Errorf("%d data problem(s) found", len(problems))
+	}
+
+	next := &CardPool{
+		Cards: make(map[int]Card, len(cards)),
+		Decks: make(map[int]Deck, len(decks)),
+	}
+	for _, card := range cards {
+		next.Cards[card.ID] = card
+	}
+	for _, deck := range decks {
+		next.Decks[deck.ID] = deck
+	}
+
+	poolMu.Lock()
+	version := 1
+	if currentPool != nil {
+		version = currentPool.Version
+	}
+	next.Version = version + 1
+	currentPool = next
+	poolMu.Unlock()
+
+	return next, nil, nil
+}
+
+// Card looks up a card definition in the pool this game started with
+func (g *Game) Card(id int) Card {
+	if g.Pool == nil {
+		return CurrentPool().Card(id)
+	}
+	return g.Pool.Card(id)
+}
+
+// card looks up a card definition in the pool of the player's game
+func (p *Player) card(id int) Card {
+	if p.pool == nil {
+		return CurrentPool().Card(id)
+	}
+	return p.pool.Card(id)
+}
+
+// card returns this field card's definition from the pool of its game
+func (fc *FieldCard) card() Card {
+	if fc.pool == nil {
+		return CurrentPool().Card(fc.CardID)
+	}
+	return fc.pool.Card(fc.CardID)
+}
@@ -0,0 +1,336 @@
+// profiles.go - Player profiles, per-deck stats and match history, saved on disk
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MatchHistoryLimit is the most matches returned by one history query
+const MatchHistoryLimit = 20
+
+// DeckRecord is a player's win/loss record with one deck
+type DeckRecord struct {
+	DeckName string `json:"deckName"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+}
+
+// Profile is what the server remembers about a player between games
+type Profile struct {
+	UID         string              `json:"uid"`
+	DisplayName string              `json:"displayName"`
+	Created     time.Time           `json:"created"`
+	Wins        int                 `json:"wins"`
+	Losses      int                 `json:"losses"`
+	Decks       map[int]*DeckRecord `json:"decks"` // By deck ID
+}
+
+// MatchPlayer is one player's side of a finished match
+type MatchPlayer struct {
+	UID      string `json:"uid"`
+	DeckID   int    `json:"deckId"`
+	DeckName string `json:"deckName"`
+	Leader   int    `json:"leader"`
+	Life     int    `json:"life"` // Life at the end of the game
+}
+
+// MatchRecord is one finished game in the match history
+type MatchRecord struct {
+	GameID    string        `json:"gameId"`
+	Players   []MatchPlayer `json:"players"`
+	Winner    string        `json:"winner"`
+	Reason    string        `json:"reason"`
+	Turns     int           `json:"turns"`
+	StartedAt time.Time     `json:"startedAt"`
+	EndedAt   time.Time     `json:"endedAt"`
+	Duration  int           `json:"duration"` // Seconds
+
+	// Ranked games only
+	Ranked        bool           `json:"ranked,omitempty"`
+	Season        string         `json:"season,omitempty"`
+	RatingChanges map[string]int `json:"ratingChanges,omitempty"` // By player UID
+
+	// Games that are part of a best-of-N match
+	MatchID    string `json:"matchId,omitempty"`
+	GameNumber int    `json:"gameNumber,omitempty"`
+
+	// Team games: everyone on the winning team, Winner included
+	Winners []string `json:"winners,omitempty"`
+}
+
+// ProfileStore keeps profiles in profiles.json, ranked ratings in ratings.json
+// and appends finished games to matches.jsonl and finished best-of-N matches
+// to match_results.jsonl
+type ProfileStore struct {
+	mu       sync.Mutex
+	dir      string
+	profiles map[string]*Profile
+	matches  []MatchRecord
+	results  []MatchResult
+	ratings  map[string]map[string]*Rating // By season, then player UID
+}
+
+// Profiles is the store used by the server (loaded at startup)
+var Profiles = NewProfileStore("data/profiles")
+
+// NewProfileStore creates an empty store that saves into dir
+func NewProfileStore(dir string) *ProfileStore {
+	return &ProfileStore{
+		dir:      dir,
+		profiles: make(map[string]*Profile),
+		ratings:  make(map[string]map[string]*Rating),
+	}
+}
+
+// Load reads profiles, ratings and match history from the store's directory
+// Missing files are not an error; they're created when first needed.
+func (ps *ProfileStore) Load() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(ps.dir, "profiles.json"))
+	if err == nil {
+		if err := json.Unmarshal(data, &ps.profiles); err != nil {
+			return fmt.Errorf("profiles.json: %v", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := ps.loadRatings(); err != nil {
+		return fmt.Errorf("ratings.json: %v", err)
+	}
+
+	err = ps.readLines("matches.jsonl", func(data []byte) error {
+		var rec MatchRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return err
+		}
+		ps.matches = append(ps.matches, rec)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ps.readLines("match_results.jsonl", func(data []byte) error {
+		var res MatchResult
+		if err := json.Unmarshal(data, &res); err != nil {
+			return err
+		}
+		ps.results = append(ps.results, res)
+		return nil
+	})
+}
+
+// readLines calls fn for each non-blank line of a JSON-lines file (caller holds ps.mu)
+// A missing file has no lines.
+func (ps *ProfileStore) readLines(name string, fn func(data []byte) error) error {
+	f, err := os.Open(filepath.Join(ps.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return fmt.Errorf("%s line %d: %v", name, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Ensure creates a profile for a player the first time they're seen
+func (ps *ProfileStore) Ensure(uid string) error {
+	if uid == "" {
+		return nil
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if _, ok := ps.profiles[uid]; ok {
+		return nil
+	}
+	ps.profile(uid)
+	return ps.saveProfiles()
+}
+
+// Get returns a copy of a player's profile
+func (ps *ProfileStore) Get(uid string) (Profile, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p, ok := ps.profiles[uid]
+	if !ok {
+		return Profile{}, false
+	}
+	cp := *p
+	cp.Decks = make(map[int]*DeckRecord, len(p.Decks))
+	for id, rec := range p.Decks {
+		r := *rec
+		cp.Decks[id] = &r
+	}
+	return cp, true
+}
+
+// SetDisplayName changes the name shown for a player
+func (ps *ProfileStore) SetDisplayName(uid, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("display name can't be empty")
+	}
+	if len(name) > 32 {
+		return fmt.Errorf("display name too long (max 32 characters)")
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.profile(uid).DisplayName = name
+	return ps.saveProfiles()
+}
+
+// History returns a player's most recent matches, newest first
+func (ps *ProfileStore) History(uid string, limit int) []MatchRecord {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := []MatchRecord{}
+	for i := len(ps.matches) - 1; i >= 0 && len(out) < limit; i-- {
+		for _, p := range ps.matches[i].Players {
+			if p.UID == uid {
+				out = append(out, ps.matches[i])
+				break
+			}
+		}
+	}
+	return out
+}
+
+// MatchResults returns a player's most recent best-of-N match results, newest first
+func (ps *ProfileStore) MatchResults(uid string, limit int) []MatchResult {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	out := []MatchResult{}
+	for i := len(ps.results) - 1; i >= 0 && len(out) < limit; i-- {
+		for _, p := range ps.results[i].Players {
+			if p == uid {
+				out = append(out, ps.results[i])
+				break
+			}
+		}
+	}
+	return out
+}
+
+// RecordMatchResult adds a finished best-of-N match to the history
+// Each game was already recorded by RecordMatch; this records the overall result.
+func (ps *ProfileStore) RecordMatchResult(res MatchResult) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.results = append(ps.results, res)
+	return ps.appendLine("match_results.jsonl", res)
+}
+
+// RecordMatch appends a finished match to the history and updates each player's stats
+// Ranked matches also update ratings; the returned record includes the rating changes.
+func (ps *ProfileStore) RecordMatch(rec MatchRecord) (MatchRecord, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if rec.Ranked {
+		rec.RatingChanges = ps.applyRatings(rec)
+		if err := ps.saveRatings(); err != nil {
+			return rec, err
+		}
+	}
+
+	ps.matches = append(ps.matches, rec)
+	for _, mp := range rec.Players {
+		p := ps.profile(mp.UID)
+		deck := p.Decks[mp.DeckID]
+		if deck == nil {
+			deck = &DeckRecord{}
+			p.Decks[mp.DeckID] = deck
+		}
+		deck.DeckName = mp.DeckName
+		if mp.UID == rec.Winner || slices.Contains(rec.Winners, mp.UID) {
+			p.Wins++
+			deck.Wins++
+		} else {
+			p.Losses++
+			deck.Losses++
+		}
+	}
+
+	if err := ps.appendMatch(rec); err != nil {
+		return rec, err
+	}
+	return rec, ps.saveProfiles()
+}
+
+// profile returns a player's profile, creating it if needed (caller holds ps.mu)
+func (ps *ProfileStore) profile(uid string) *Profile {
+	p, ok := ps.profiles[uid]
+	if !ok {
+		p = &Profile{UID: uid, DisplayName: uid, Created: time.Now()}
+		ps.profiles[uid] = p
+	}
+	if p.Decks == nil {
+		p.Decks = make(map[int]*DeckRecord)
+	}
+	return p
+}
+
+// saveProfiles rewrites profiles.json (caller holds ps.mu)
+func (ps *ProfileStore) saveProfiles() error {
+	data, err := json.MarshalIndent(ps.profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(ps.dir, "profiles.json"), data)
+}
+
+// writeFileAtomic writes to a temp file and renames it, so a crash can't leave a half-written file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// appendMatch adds one line to matches.jsonl (caller holds ps.mu)
+func (ps *ProfileStore) appendMatch(rec MatchRecord) error {
+	return ps.appendLine("matches.jsonl", rec)
+}
+
+// appendLine adds one JSON line to a file in the store's directory (caller holds ps.mu)
+func (ps *ProfileStore) appendLine(name string, v interface{}) error {
+	if err := os.MkdirAll(ps.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(ps.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,153 @@
+// ratings.go - Elo ratings for ranked games, tracked per season
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const DefaultRating = 1500
+const EloK = 32             // Largest rating change from one game
+const ProvisionalGames = 10 // Players with fewer ranked games move twice as fast
+const LeaderboardSize = 50
+
+// SeasonName overrides the season ratings are recorded under (empty = the current month, "2006-01")
+var SeasonName = ""
+
+// CurrentSeason returns the season new ranked results count towards
+func CurrentSeason() string {
+	if SeasonName != "" {
+		return SeasonName
+	}
+	return time.Now().Format("2006-01")
+}
+
+// Rating is a player's ranked standing in one season
+type Rating struct {
+	UID    string `json:"uid"`
+	Rating int    `json:"rating"`
+	Peak   int    `json:"peak"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+}
+
+// Games returns how many ranked games the rating is based on
+func (r *Rating) Games() int {
+	return r.Wins + r.Losses
+}
+
+// eloChange returns how much the winner gains (and the loser loses) for a result
+// The expected score uses the standard 400-point logistic curve.
+func eloChange(winner, loser *Rating) (int, int) {
+	expected := 1 / (1 + math.Pow(10, float64(loser.Rating-winner.Rating)/400))
+	k := func(r *Rating) float64 {
+		if r.Games() < ProvisionalGames {
+			return 2 * EloK
+		}
+		return EloK
+	}
+	gain := int(math.Round(k(winner) * (1 - expected)))
+	loss := int(math.Round(k(loser) * (1 - expected)))
+	return max(gain, 1), max(loss, 1)
+}
+
+// seasonRating returns a player's rating for a season, starting them at DefaultRating (caller holds ps.mu)
+func (ps *ProfileStore) seasonRating(season, uid string) *Rating {
+	if ps.ratings == nil {
+		ps.ratings = make(map[string]map[string]*Rating)
+	}
+	if ps.ratings[season] == nil {
+		ps.ratings[season] = make(map[string]*Rating)
+	}
+	r, ok := ps.ratings[season][uid]
+	if !ok {
+		r = &Rating{UID: uid, Rating: DefaultRating, Peak: DefaultRating}
+		ps.ratings[season][uid] = r
+	}
+	return r
+}
+
+// applyRatings updates ratings for a ranked two-player match and returns each player's change (caller holds ps.mu)
+func (ps *ProfileStore) applyRatings(rec MatchRecord) map[string]int {
+	if len(rec.Players) != 2 || rec.Winner == "" {
+		return nil
+	}
+	var winner, loser *Rating
+	for _, p := range rec.Players {
+		if p.UID == rec.Winner {
+			winner = ps.seasonRating(rec.Season, p.UID)
+		} else {
+			loser = ps.seasonRating(rec.Season, p.UID)
+		}
+	}
+	if winner == nil || loser == nil {
+		return nil
+	}
+
+	gain, loss := eloChange(winner, loser)
+	winner.Rating += gain
+	winner.Peak = max(winner.Peak, winner.Rating)
+	winner.Wins++
+	loser.Rating -= loss
+	loser.Losses++
+
+	return map[string]int{winner.UID: gain, loser.UID: -loss}
+}
+
+// Rating returns a copy of a player's rating for a season, and whether they've played ranked in it
+func (ps *ProfileStore) Rating(season, uid string) (Rating, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	r, ok := ps.ratings[season][uid]
+	if !ok {
+		return Rating{UID: uid, Rating: DefaultRating, Peak: DefaultRating}, false
+	}
+	return *r, true
+}
+
+// Leaderboard returns the top rated players of a season, highest first
+func (ps *ProfileStore) Leaderboard(season string, limit int) []Rating {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	out := []Rating{}
+	for _, r := range ps.ratings[season] {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rating != out[j].Rating {
+			return out[i].Rating > out[j].Rating
+		}
+		return out[i].UID < out[j].UID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// loadRatings reads ratings.json (caller holds ps.mu)
+func (ps *ProfileStore) loadRatings() error {
+	data, err := os.ReadFile(filepath.Join(ps.dir, "ratings.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &ps.ratings)
+}
+
+// saveRatings rewrites ratings.json (caller holds ps.mu)
+func (ps *ProfileStore) saveRatings() error {
+	data, err := json.MarshalIndent(ps.ratings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(ps.dir, "ratings.json"), data)
+}
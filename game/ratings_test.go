@@ -0,0 +1,63 @@
+package game
+
+import "testing"
+
+func TestEloChange(t *testing.T) {
+	// established has played enough ranked games to leave the provisional K
+	established := func(rating int) *Rating {
+		return &Rating{Rating: rating, Wins: ProvisionalGames}
+	}
+	provisional := func(rating int) *Rating {
+		return &Rating{Rating: rating, Wins: ProvisionalGames - 1}
+	}
+
+	tests := []struct {
+		name          string
+		winner, loser *Rating
+		gain, loss    int
+	}{
+		{"equal ratings", established(1500), established(1500), 16, 16},
+		{"equal provisional ratings move twice as fast", provisional(1500), provisional(1500), 32, 32},
+		{"provisional winner", provisional(1500), established(1500), 32, 16},
+		{"provisional loser", established(1500), provisional(1500), 16, 32},
+		{"favorite wins", established(1900), established(1500), 3, 3},
+		{"upset", established(1500), established(1900), 29, 29},
+		{"a result always moves ratings", established(2700), established(1500), 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gain, loss := eloChange(tt.winner, tt.loser)
+			if gain != tt.gain || loss != tt.loss {
+				t.Errorf("gain %d, loss %d; want %d, %d", gain, loss, tt.gain, tt.loss)
+			}
+		})
+	}
+}
+
+func TestApplyRatings(t *testing.T) {
+	ps := NewProfileStore(t.TempDir())
+	rec := MatchRecord{
+		Winner:  "a",
+		Ranked:  true,
+		Season:  "s1",
+		Players: []MatchPlayer{{UID: "a"}, {UID: "b"}},
+	}
+
+	// Both are new, so both move by half the doubled provisional K
+	changes := ps.applyRatings(rec)
+	if changes["a"] != EloK || changes["b"] != -EloK {
+		t.Fatalf("first game changes %v", changes)
+	}
+	a, b := ps.seasonRating("s1", "a"), ps.seasonRating("s1", "b")
+	if a.Rating != DefaultRating+EloK || a.Peak != a.Rating || a.Wins != 1 {
+		t.Errorf("winner %+v", a)
+	}
+	if b.Rating != DefaultRating-EloK || b.Peak != DefaultRating || b.Losses != 1 {
+		t.Errorf("loser %+v", b)
+	}
+
+	if changes := ps.applyRatings(MatchRecord{Winner: "a", Players: []MatchPlayer{{UID: "a"}, {UID: "b"}, {UID: "c"}}}); changes != nil {
+		t.Errorf("rated a three-player game: %v", changes)
+	}
+}
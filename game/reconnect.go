@@ -0,0 +1,131 @@
+// reconnect.go - The game state sent to a player who reconnects
+package game
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// ReconnectEvents encodes what a reconnecting player needs to redraw the game
+// It's built and encoded under the game lock, so it can't see an action half applied.
+// ok is false when the player can't rejoin; resp is then the Error event to send instead.
+func (g *Game) ReconnectEvents(playerUID string) (resp []byte, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player, exists := g.Players[playerUID]
+	if !exists {
+		resp, _ = json.Marshal([]Event{
+			{Type: "Error", Data: map[string]interface{}{"message": "You are not in this game"}},
+		})
+		return resp, false
+	}
+	if g.Winner != "" {
+		resp, _ = json.Marshal([]Event{
+			{Type: "Error", Data: map[string]interface{}{"message": "Game is already over", "winner": g.Winner}},
+		})
+		return resp, false
+	}
+
+	// The opponent is the first other player in turn order who isn't a teammate;
+	// every player is listed in "players"
+	var opponentUID string
+	var opponent *Player
+	teammates := g.Teammates(playerUID)
+	for _, uid := range g.TurnOrder {
+		if uid != playerUID && !slices.Contains(teammates, uid) && opponent == nil {
+			opponentUID = uid
+			opponent = g.Players[uid]
+		}
+	}
+
+	myCreatures, myLands := g.splitField(player)
+	opponentCreatures := []*FieldCard{}
+	opponentLands := []*FieldCard{}
+	opponentLife := g.Rules.Life
+	opponentLeader := 0
+	opponentLeaderTax := 0
+	opponentLeaderPowerUsed := false
+	if opponent != nil {
+		opponentCreatures, opponentLands = g.splitField(opponent)
+		opponentLife = opponent.Life
+		opponentLeader = opponent.Leader
+		opponentLeaderTax = opponent.LeaderTax()
+		opponentLeaderPowerUsed = opponent.LeaderPowerUsed
+	}
+
+	resp, _ = json.Marshal([]Event{
+		{
+			Type: "GameReconnected",
+			Data: map[string]interface{}{
+				"gameId":                  g.ID,
+				"playerUid":               playerUID,
+				"opponentUid":             opponentUID,
+				"currentTurn":             g.Turn,
+				"started":                 g.Started,
+				"drawPhase":               g.DrawPhase,
+				"mulliganPhase":           g.MulliganPhase,
+				"mulliganDecided":         g.MulliganDecisions[playerUID],
+				"myHand":                  player.Hand,
+				"myLife":                  player.Life,
+				"myField":                 myCreatures,
+				"myLands":                 myLands,
+				"myManaPool":              player.ManaPool,
+				"myDeckSize":              len(player.DrawPile),
+				"myVaultSize":             len(player.VaultPile),
+				"myDiscardSize":           len(player.Discard),
+				"myLeader":                player.Leader,
+				"myLeaderTax":             player.LeaderTax(),
+				"myLeaderPowerUsed":       player.LeaderPowerUsed,
+				"opponentLife":            opponentLife,
+				"opponentField":           opponentCreatures,
+				"opponentLands":           opponentLands,
+				"opponentLeader":          opponentLeader,
+				"opponentLeaderTax":       opponentLeaderTax,
+				"opponentLeaderPowerUsed": opponentLeaderPowerUsed,
+				"combatPhase":             g.CombatPhase,
+				"priorityPlayer":          g.PriorityPlayer,
+				"attackingPlayer":         g.AttackingPlayer,
+				"pendingAttacks":          g.PendingAttacks,
+				"turnOrder":               g.TurnOrder,
+				"players":                 g.playerBoards(),
+				"teams":                   g.Teams,
+				"sharedLife":              g.SharedLife,
+				"rules":                   g.Rules,
+			},
+		},
+	})
+	return resp, true
+}
+
+// splitField separates a player's field into creatures and lands (caller holds g.mu)
+func (g *Game) splitField(p *Player) (creatures, lands []*FieldCard) {
+	creatures = []*FieldCard{}
+	lands = []*FieldCard{}
+	for _, fc := range p.Field {
+		if g.Card(fc.CardID).CardType == "Land" {
+			lands = append(lands, fc)
+		} else {
+			creatures = append(creatures, fc)
+		}
+	}
+	return creatures, lands
+}
+
+// playerBoards describes every player's public state, for games with more than two players (caller holds g.mu)
+func (g *Game) playerBoards() map[string]interface{} {
+	boards := map[string]interface{}{}
+	for uid, p := range g.Players {
+		creatures, lands := g.splitField(p)
+		boards[uid] = map[string]interface{}{
+			"life":       p.Life,
+			"field":      creatures,
+			"lands":      lands,
+			"leader":     p.Leader,
+			"leaderTax":  p.LeaderTax(),
+			"handSize":   len(p.Hand),
+			"eliminated": p.Eliminated,
+		}
+	}
+	return boards
+}
@@ -7,7 +7,7 @@ import "time"
 func (g *Game) checkPriority(playerUID string, actionType string) bool {
 	// During response window, only priority player can act
 	if g.CombatPhase == "response_window" {
-		if actionType == "play_instant" || actionType == "pass_priority" || actionType == "tap_card" || actionType == "burn_card" {
+		if actionType == "play_instant" || actionType == "pass_priority" || actionType == "tap_card" || actionType == "burn_card" || actionType == "activate_ability" {
 			return playerUID == g.PriorityPlayer
 		}
 		return false
@@ -16,11 +16,7 @@ func (g *Game) checkPriority(playerUID string, actionType string) bool {
 	// During combat, special rules apply
 	if g.CombatPhase == "attackers_declared" {
 		if actionType == "declare_blockers" {
-			for uid := range g.Players {
-				if uid != g.AttackingPlayer {
-					return playerUID == uid
-				}
-			}
+			return g.isOpponent(g.AttackingPlayer, playerUID)
 		}
 		return false
 	}
@@ -31,11 +27,16 @@ func (g *Game) checkPriority(playerUID string, actionType string) bool {
 
 // HandleAction is the main entry point for all game actions
 func (g *Game) HandleAction(a Action) []Event {
+	return g.runLocked(func() []Event { return g.handleAction(a) })
+}
+
+// handleAction applies an action (caller holds g.mu)
+func (g *Game) handleAction(a Action) []Event {
 	g.LastActivity = time.Now()
 
 	// Game already over?
 	if g.Winner != "" {
-		return []Event{{Type: "GameOver", Data: map[string]interface{}{"winner": g.Winner}}}
+		return []Event{{Type: "GameOver", Data: map[string]interface{}{"winner": g.Winner, "reason": g.EndReason}}}
 	}
 
 	// Handle mulligan phase
@@ -77,7 +78,13 @@ func (g *Game) HandleAction(a Action) []Event {
 		}
 	}
 
-	// Route to appropriate handler
+	// Every action can end the game (damage, life costs), so check once it resolves
+	events := g.routeAction(a)
+	return append(events, g.checkGameOver()...)
+}
+
+// routeAction sends an action to its handler
+func (g *Game) routeAction(a Action) []Event {
 	switch a.Type {
 	case "end_turn":
 		return g.endTurn(a)
@@ -95,6 +102,10 @@ func (g *Game) HandleAction(a Action) []Event {
 		return g.playInstant(a)
 	case "pass_priority":
 		return g.passPriority(a)
+	case "activate_ability":
+		return g.activateAbility(a)
+	case "use_leader_power":
+		return g.useLeaderPower(a)
 	default:
 		return []Event{
 			{
@@ -113,8 +124,7 @@ func (g *Game) endTurn(a Action) []Event {
 	endingPlayer := g.Players[g.Turn]
 	for _, fc := range endingPlayer.Field {
 		if fc.IsTapped() {
-			card := CardDB[fc.CardID]
-			if card.HasAbility("Vigilance") {
+			if fc.HasAbility("Vigilance") && !fc.HasStatus(StatusFrozen) {
 				fc.SetTapped(false)
 				events = append(events, Event{
 					Type: "CardUntapped",
@@ -128,33 +138,51 @@ func (g *Game) endTurn(a Action) []Event {
 		}
 	}
 
-	// Switch turn
-	for uid := range g.Players {
-		if uid != g.Turn {
-			g.Turn = uid
-			break
-		}
+	// Expire end-of-turn and turn-counted effects and statuses
+	events = append(events, g.expireTurnEffects(g.Turn)...)
+	events = append(events, g.tickStatuses(g.Turn)...)
+	for uid, p := range g.Players {
+		events = append(events, g.handleDeaths(p, uid)...)
 	}
 
+	// Discard down to the rule set's hand size limit
+	events = append(events, g.enforceHandLimit(g.Turn)...)
+
+	// Next player in turn order who's still in the game
+	g.Turn = g.nextAlive(g.Turn)
+	g.TurnNumber++
+	g.TurnStartedAt = time.Now()
+
 	activePlayer := g.Players[g.Turn]
 
-	// Start of turn: untap, clear sickness, clear mana
+	// Start of turn: untap (unless frozen), clear sickness, clear mana
+	frozen := []int{}
 	for _, fc := range activePlayer.Field {
-		fc.SetTapped(false)
+		if fc.HasStatus(StatusFrozen) {
+			frozen = append(frozen, fc.InstanceID)
+		} else {
+			fc.SetTapped(false)
+		}
 		fc.CanAttack = true
 		fc.Status["Summoned"] = 0
 	}
 	activePlayer.ManaPool.Clear()
 	activePlayer.LandsPlayedThisTurn = 0
+	activePlayer.LeaderPowerUsed = false
 
 	events = append(events, Event{
 		Type: "TurnChanged",
 		Data: map[string]interface{}{
 			"activePlayer": g.Turn,
 			"manaPool":     activePlayer.ManaPool,
+			"frozen":       frozen,
 		},
 	})
 
+	// Start-of-turn statuses (poison)
+	events = append(events, g.applyStartOfTurnStatuses(g.Turn)...)
+	events = append(events, g.handleDeaths(activePlayer, g.Turn)...)
+
 	// Enter draw phase
 	g.DrawPhase = true
 	events = append(events, Event{
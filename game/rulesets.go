@@ -0,0 +1,194 @@
+// rulesets.go - Rule sets: the life totals, draws, limits and timers a game is played with
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Mulligan policies
+const (
+	MulliganFree     = "free"      // Redraw a full hand
+	MulliganMinusOne = "minus_one" // Redraw with one main deck card fewer
+	MulliganNone     = "none"      // No mulligans
+)
+
+// RuleSet holds the numbers one game is played with, chosen when the game is created
+type RuleSet struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	Life           int `json:"life"`
+	SharedTeamLife int `json:"sharedTeamLife"` // Life of a team sharing one life total
+
+	// Opening hand and mulligans
+	InitialMainDeckDraw int    `json:"initialMainDeckDraw"`
+	InitialVaultDraw    int    `json:"initialVaultDraw"`
+	Mulligan            string `json:"mulligan"` // MulliganFree, MulliganMinusOne or MulliganNone
+
+	// Turns
+	LandsPerTurn int `json:"landsPerTurn"`
+	MinHandLimit int `json:"minHandLimit"` // Minimum hand size to draw up to
+	MaxHandSize  int `json:"maxHandSize"`  // Cards over this are discarded at the end of your turn (0 = no limit)
+	TurnSeconds  int `json:"turnSeconds"`  // The turn ends on its own after this long (0 = no timer)
+
+	// Deck building
+	MaxMainDeckSize  int `json:"maxMainDeckSize"`
+	MaxVaultSize     int `json:"maxVaultSize"`
+	MaxCopiesPerCard int `json:"maxCopiesPerCard"` // Copies of one card in a main deck (0 = no limit); vault lands are not limited
+}
+
+// DefaultRules are the standard rules: games that don't pick a rule set and the deck builder use them
+// A "standard" preset in the rule sets file replaces them.
+var DefaultRules = RuleSet{
+	Name:                "standard",
+	Life:                30,
+	SharedTeamLife:      45,
+	InitialMainDeckDraw: 5,
+	InitialVaultDraw:    2,
+	Mulligan:            MulliganFree,
+	LandsPerTurn:        1,
+	MinHandLimit:        1,
+	MaxMainDeckSize:     30,
+	MaxVaultSize:        15,
+	MaxCopiesPerCard:    3,
+}
+
+// RuleSetDB holds the named presets, in the order they're listed
+var RuleSetDB = []RuleSet{DefaultRules}
+
+// LoadRuleSets reads the rule set presets from a JSON array
+// Settings a preset leaves out are taken from the standard rules.
+func LoadRuleSets(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	base := DefaultRules
+	presets := []RuleSet{}
+	seen := map[string]bool{}
+	for i, r := range raw {
+		rules := base
+		rules.Name = ""
+		rules.Description = ""
+		if err := json.Unmarshal(r, &rules); err != nil {
+			return fmt.Errorf("rule set %d: %v", i+1, err)
+		}
+		if err := rules.check(); err != nil {
+			return fmt.Errorf("rule set %q: %v", rules.Name, err)
+		}
+		if seen[rules.Name] {
+			return fmt.Errorf("rule set %q is defined twice", rules.Name)
+		}
+		seen[rules.Name] = true
+		if rules.Name == base.Name {
+			DefaultRules = rules
+		}
+		presets = append(presets, rules)
+	}
+	if !seen[base.Name] {
+		presets = append([]RuleSet{DefaultRules}, presets...)
+	}
+
+	RuleSetDB = presets
+	return nil
+}
+
+// check validates a rule set's settings
+func (r *RuleSet) check() error {
+	switch {
+	case r.Name == "":
+		return fmt.Errorf("missing name")
+	case r.Life <= 0 || r.SharedTeamLife <= 0:
+		return fmt.Errorf("life must be above 0")
+	case r.InitialMainDeckDraw < 0 || r.InitialVaultDraw < 0 || r.LandsPerTurn < 0 || r.MinHandLimit < 0:
+		return fmt.Errorf("draws and limits can't be negative")
+	case r.MaxHandSize < 0 || r.TurnSeconds < 0 || r.MaxCopiesPerCard < 0:
+		return fmt.Errorf("hand size, turn timer and copy limit can't be negative")
+	case r.MaxMainDeckSize <= 0 || r.MaxVaultSize <= 0:
+		return fmt.Errorf("deck sizes must be above 0")
+	}
+	switch r.Mulligan {
+	case MulliganFree, MulliganMinusOne, MulliganNone:
+		return nil
+	}
+	return fmt.Errorf("unknown mulligan policy %q", r.Mulligan)
+}
+
+// FindRuleSet looks up a preset by name (empty = the standard rules)
+func FindRuleSet(name string) (RuleSet, error) {
+	if name == "" {
+		return DefaultRules, nil
+	}
+	for _, rules := range RuleSetDB {
+		if rules.Name == name {
+			return rules, nil
+		}
+	}
+	return RuleSet{}, fmt.Errorf("unknown rule set %q", name)
+}
+
+// mulliganDraw returns how many main deck cards a mulligan draws
+func (r *RuleSet) mulliganDraw() int {
+	if r.Mulligan == MulliganMinusOne {
+		return max(r.InitialMainDeckDraw-1, 0)
+	}
+	return r.InitialMainDeckDraw
+}
+
+// enforceHandLimit discards the cards a player drew last until they're down to the hand size limit
+func (g *Game) enforceHandLimit(playerUID string) []Event {
+	p := g.Players[playerUID]
+	limit := g.Rules.MaxHandSize
+	if limit == 0 || len(p.Hand) <= limit {
+		return nil
+	}
+
+	discarded := append([]int{}, p.Hand[limit:]...)
+	p.Hand = p.Hand[:limit]
+	p.Discard = append(p.Discard, discarded...)
+	return []Event{{
+		Type: "HandLimitDiscard",
+		Data: map[string]interface{}{
+			"player":    playerUID,
+			"discarded": discarded,
+			"handSize":  len(p.Hand),
+		},
+	}}
+}
+
+// TimeOutTurn ends the current turn if it has run past the rule set's turn timer
+// Turns aren't cut off in the middle of combat.
+func (g *Game) TimeOutTurn(now time.Time) []Event {
+	return g.runLocked(func() []Event { return g.timeOutTurn(now) })
+}
+
+// timeOutTurn ends a turn that has run past the timer (caller holds g.mu)
+func (g *Game) timeOutTurn(now time.Time) []Event {
+	if g.Rules.TurnSeconds == 0 || !g.Started || g.Winner != "" || g.CombatPhase != "" {
+		return nil
+	}
+	if now.Sub(g.TurnStartedAt) < time.Duration(g.Rules.TurnSeconds)*time.Second {
+		return nil
+	}
+
+	timedOut := g.Turn
+	g.DrawPhase = false
+	events := []Event{{
+		Type: "TurnTimedOut",
+		Data: map[string]interface{}{
+			"player":      timedOut,
+			"turnSeconds": g.Rules.TurnSeconds,
+		},
+	}}
+	events = append(events, g.endTurn(Action{PlayerUID: timedOut})...)
+	return append(events, g.checkGameOver()...)
+}
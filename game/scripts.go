@@ -19,6 +19,7 @@ type ScriptContext struct {
 	CasterUID  string      // UID of the caster
 	Target     *FieldCard  // Target creature (if any)
 	TargetUID  string      // Target player UID (if targeting a player)
+	X          int         // Value chosen for X when the card was cast
 }
 
 // ExecuteScript parses and executes a card's custom script
@@ -49,6 +50,13 @@ func ExecuteScript(script string, ctx *ScriptContext) []Event {
 			continue
 		}
 
+		// Substitute the chosen X value, e.g. DamageCreature(X, 'target')
+		for i, arg := range args {
+			if strings.EqualFold(arg, "X") {
+				args[i] = strconv.Itoa(ctx.X)
+			}
+		}
+
 		// Execute the function
 		result := executeFunction(funcName, args, ctx)
 		events = append(events, result...)
@@ -123,43 +131,68 @@ func parseArgs(argsStr string) []string {
 	return args
 }
 
+// scriptNeedsTarget reports whether a script refers to a chosen target creature
+func scriptNeedsTarget(script string) bool {
+	lower := strings.ToLower(script)
+	return strings.Contains(lower, "'target'") || strings.Contains(lower, "\"target\"")
+}
+
 // ============================================================================
 // FUNCTION DISPATCHER & RESOLUTION
 // ============================================================================
 
+// scriptFunctions maps lowercase script function names to their implementations
+var scriptFunctions = map[string]func([]string, *ScriptContext) []Event{
+	"draw":           scriptDraw,
+	"damage":         scriptDamage,
+	"heal":           scriptHeal,
+	"buff":           scriptBuff,
+	"gainmana":       scriptGainMana,
+	"discard":        scriptDiscard,
+	"destroy":        scriptDestroy,
+	"damagecreature": scriptDamageCreature,
+	"tapcreature":    scriptTapCreature,
+	"bounce":         scriptBounce,
+	"applystatus":    scriptApplyStatus,
+	"removestatus":   scriptRemoveStatus,
+}
+
 // executeFunction executes a script function and returns events
 func executeFunction(funcName string, args []string, ctx *ScriptContext) []Event {
-	switch strings.ToLower(funcName) {
-	case "draw":
-		return scriptDraw(args, ctx)
-	case "damage":
-		return scriptDamage(args, ctx)
-	case "heal":
-		return scriptHeal(args, ctx)
-	case "buff":
-		return scriptBuff(args, ctx)
-	case "gainmana":
-		return scriptGainMana(args, ctx)
-	case "discard":
-		return scriptDiscard(args, ctx)
-	case "destroy":
-		return scriptDestroy(args, ctx)
-	case "damagecreature":
-		return scriptDamageCreature(args, ctx)
-	case "tapcreature":
-		return scriptTapCreature(args, ctx)
-	case "bounce":
-		return scriptBounce(args, ctx)
-	default:
+	fn, ok := scriptFunctions[strings.ToLower(funcName)]
+	if !ok {
 		return []Event{{
 			Type: "ScriptError",
 			Data: map[string]interface{}{"error": fmt.Sprintf("unknown function: %s", funcName)},
 		}}
 	}
+	return fn(args, ctx)
+}
+
+// ValidateScript checks that every command in a script parses and calls a known function
+func ValidateScript(script string) []error {
+	errs := []error{}
+	for _, cmd := range splitCommands(script) {
+		cmd = strings.TrimSpace(cmd)
+		if cmd == "" {
+			continue
+		}
+		funcName, _, err := parseFunction(cmd)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, ok := scriptFunctions[strings.ToLower(funcName)]; !ok {
+			errs = append(errs, fmt.Errorf("unknown function: %s", funcName))
+		}
+	}
+	return errs
 }
 
 // resolvePlayer resolves a player reference string to actual player
 // Accepts: "caster", "opponent", "target", or a player UID
+// With several opponents, "opponent" is the chosen target player if that's an
+// opponent, otherwise the next opponent in turn order.
 func resolvePlayer(ref string, ctx *ScriptContext) (*Player, string, error) {
 	ref = strings.ToLower(strings.TrimSpace(ref))
 
@@ -167,10 +200,11 @@ func resolvePlayer(ref string, ctx *ScriptContext) (*Player, string, error) {
 	case "caster", "self", "owner":
 		return ctx.Caster, ctx.CasterUID, nil
 	case "opponent", "enemy":
-		for uid, p := range ctx.Game.Players {
-			if uid != ctx.CasterUID {
-				return p, uid, nil
-			}
+		if ctx.Game.isOpponent(ctx.CasterUID, ctx.TargetUID) {
+			return ctx.Game.Players[ctx.TargetUID], ctx.TargetUID, nil
+		}
+		if opponents := ctx.Game.opponentsOf(ctx.CasterUID); len(opponents) > 0 {
+			return ctx.Game.Players[opponents[0]], opponents[0], nil
 		}
 		return nil, "", fmt.Errorf("no opponent found")
 	case "target":
@@ -190,13 +224,52 @@ func resolvePlayer(ref string, ctx *ScriptContext) (*Player, string, error) {
 	}
 }
 
+// resolvePlayers resolves a reference that can name several players
+// Accepts everything resolvePlayer does, plus "each_opponent" and "each_player"
+// (players still in the game, in turn order).
+func resolvePlayers(ref string, ctx *ScriptContext) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(ref)) {
+	case "each_opponent", "all_opponents":
+		return ctx.Game.opponentsOf(ctx.CasterUID), nil
+	case "each_player", "all_players":
+		return ctx.Game.alivePlayers(), nil
+	}
+	_, uid, err := resolvePlayer(ref, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []string{uid}, nil
+}
+
+// isPlayerGroup reports whether a reference names a group of players
+func isPlayerGroup(ref string) bool {
+	switch strings.ToLower(strings.TrimSpace(ref)) {
+	case "each_opponent", "all_opponents", "each_player", "all_players":
+		return true
+	}
+	return false
+}
+
+// forEachPlayer runs fn for every player a reference resolves to and collects the events
+func forEachPlayer(ref string, ctx *ScriptContext, fn func(player *Player, playerUID string) []Event) []Event {
+	uids, err := resolvePlayers(ref, ctx)
+	if err != nil {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
+	}
+	events := []Event{}
+	for _, uid := range uids {
+		events = append(events, fn(ctx.Game.Players[uid], uid)...)
+	}
+	return events
+}
+
 // ============================================================================
 // SCRIPT FUNCTIONS
 // ============================================================================
 
 // scriptDraw: Draw(count, source, target)
 // source: "main", "vault"
-// target: "caster", "opponent"
+// target: "caster", "opponent", "each_opponent", "each_player"
 func scriptDraw(args []string, ctx *ScriptContext) []Event {
 	if len(args) < 3 {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Draw requires 3 arguments: count, source, target"}}}
@@ -208,36 +281,34 @@ func scriptDraw(args []string, ctx *ScriptContext) []Event {
 	}
 
 	source := strings.ToLower(strings.TrimSpace(args[1]))
-	player, playerUID, err := resolvePlayer(args[2], ctx)
-	if err != nil {
-		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
-	}
-
-	var drawn []int
-	switch source {
-	case "main", "deck":
-		drawn = player.DrawCards(count)
-	case "vault", "land":
-		drawn = player.DrawFromVault(count)
-	default:
+	fromVault := source == "vault" || source == "land"
+	if !fromVault && source != "main" && source != "deck" {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid source: " + source}}}
 	}
 
-	return []Event{{
-		Type: "ScriptDraw",
-		Data: map[string]interface{}{
-			"player":       playerUID,
-			"source":       source,
-			"count":        len(drawn),
-			"cards":        drawn,
-			"mainDeckSize": len(player.DrawPile),
-			"vaultSize":    len(player.VaultPile),
-		},
-	}}
+	return forEachPlayer(args[2], ctx, func(player *Player, playerUID string) []Event {
+		var drawn []int
+		if fromVault {
+			drawn = player.DrawFromVault(count)
+		} else {
+			drawn = player.DrawCards(count)
+		}
+		return []Event{{
+			Type: "ScriptDraw",
+			Data: map[string]interface{}{
+				"player":       playerUID,
+				"source":       source,
+				"count":        len(drawn),
+				"cards":        drawn,
+				"mainDeckSize": len(player.DrawPile),
+				"vaultSize":    len(player.VaultPile),
+			},
+		}}
+	})
 }
 
 // scriptDamage: Damage(amount, target)
-// target: "opponent", "target" (creature), creature instanceId
+// target: "opponent", "each_opponent", "each_player", "target" (creature), creature instanceId
 func scriptDamage(args []string, ctx *ScriptContext) []Event {
 	if len(args) < 2 {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Damage requires 2 arguments: amount, target"}}}
@@ -251,51 +322,34 @@ func scriptDamage(args []string, ctx *ScriptContext) []Event {
 	targetRef := strings.ToLower(strings.TrimSpace(args[1]))
 
 	// Check if targeting a player
-	if targetRef == "opponent" || targetRef == "enemy" {
-		player, playerUID, err := resolvePlayer(targetRef, ctx)
-		if err != nil {
-			return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
-		}
-		player.Life -= amount
-
-		events := []Event{{
-			Type: "ScriptDamage",
-			Data: map[string]interface{}{
-				"targetType":   "player",
-				"targetPlayer": playerUID,
-				"amount":       amount,
-				"newLife":      player.Life,
-			},
-		}}
-
-		// Check for death
-		if player.Life <= 0 {
-			for uid := range ctx.Game.Players {
-				if uid != playerUID {
-					ctx.Game.Winner = uid
-					events = append(events, Event{
-						Type: "GameOver",
-						Data: map[string]interface{}{"winner": uid},
-					})
-					break
-				}
-			}
-		}
-		return events
+	// A player at 0 life loses when the game checks after the script resolves
+	if targetRef == "opponent" || targetRef == "enemy" || isPlayerGroup(targetRef) {
+		return forEachPlayer(targetRef, ctx, func(player *Player, playerUID string) []Event {
+			ctx.Game.changeLife(playerUID, -amount)
+			return []Event{{
+				Type: "ScriptDamage",
+				Data: map[string]interface{}{
+					"targetType":   "player",
+					"targetPlayer": playerUID,
+					"amount":       amount,
+					"newLife":      player.Life,
+				},
+			}}
+		})
 	}
 
 	// Check if targeting a creature
 	if targetRef == "target" && ctx.Target != nil {
-		ctx.Target.CurrentHealth -= amount
-		return []Event{{
+		dealt, statusEvents := ctx.Target.applyDamage(amount)
+		return append([]Event{{
 			Type: "ScriptDamage",
 			Data: map[string]interface{}{
 				"targetType":       "creature",
 				"targetInstanceId": ctx.Target.InstanceID,
-				"amount":           amount,
+				"amount":           dealt,
 				"newHealth":        ctx.Target.CurrentHealth,
 			},
-		}}
+		}}, statusEvents...)
 	}
 
 	// Try as instance ID
@@ -305,16 +359,16 @@ func scriptDamage(args []string, ctx *ScriptContext) []Event {
 		for _, player := range ctx.Game.Players {
 			for _, fc := range player.Field {
 				if fc.InstanceID == instanceID {
-					fc.CurrentHealth -= amount
-					return []Event{{
+					dealt, statusEvents := fc.applyDamage(amount)
+					return append([]Event{{
 						Type: "ScriptDamage",
 						Data: map[string]interface{}{
 							"targetType":       "creature",
 							"targetInstanceId": fc.InstanceID,
-							"amount":           amount,
+							"amount":           dealt,
 							"newHealth":        fc.CurrentHealth,
 						},
-					}}
+					}}, statusEvents...)
 				}
 			}
 		}
@@ -324,7 +378,7 @@ func scriptDamage(args []string, ctx *ScriptContext) []Event {
 }
 
 // scriptHeal: Heal(amount, target)
-// target: "caster", "opponent", "target" (creature)
+// target: "caster", "opponent", "each_opponent", "each_player", "target" (creature)
 func scriptHeal(args []string, ctx *ScriptContext) []Event {
 	if len(args) < 2 {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Heal requires 2 arguments: amount, target"}}}
@@ -338,21 +392,19 @@ func scriptHeal(args []string, ctx *ScriptContext) []Event {
 	targetRef := strings.ToLower(strings.TrimSpace(args[1]))
 
 	// Check if targeting a player
-	if targetRef == "caster" || targetRef == "self" || targetRef == "opponent" {
-		player, playerUID, err := resolvePlayer(targetRef, ctx)
-		if err != nil {
-			return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
-		}
-		player.Life += amount
-		return []Event{{
-			Type: "ScriptHeal",
-			Data: map[string]interface{}{
-				"targetType":   "player",
-				"targetPlayer": playerUID,
-				"amount":       amount,
-				"newLife":      player.Life,
-			},
-		}}
+	if targetRef == "caster" || targetRef == "self" || targetRef == "opponent" || isPlayerGroup(targetRef) {
+		return forEachPlayer(targetRef, ctx, func(player *Player, playerUID string) []Event {
+			ctx.Game.changeLife(playerUID, amount)
+			return []Event{{
+				Type: "ScriptHeal",
+				Data: map[string]interface{}{
+					"targetType":   "player",
+					"targetPlayer": playerUID,
+					"amount":       amount,
+					"newLife":      player.Life,
+				},
+			}}
+		})
 	}
 
 	// Check if targeting a creature
@@ -376,8 +428,10 @@ func scriptHeal(args []string, ctx *ScriptContext) []Event {
 	return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid target: " + targetRef}}}
 }
 
-// scriptBuff: Buff(attack, health, target)
+// scriptBuff: Buff(attack, health, target, duration, turns)
 // Modifies a creature's attack and health modifiers
+// duration (optional): "permanent" (default), "end_of_turn", "turns", "while_source"
+// turns: number of the caster's turns the buff lasts (only for "turns")
 func scriptBuff(args []string, ctx *ScriptContext) []Event {
 	if len(args) < 3 {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Buff requires 3 arguments: attack, health, target"}}}
@@ -419,20 +473,62 @@ func scriptBuff(args []string, ctx *ScriptContext) []Event {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid target: " + targetRef}}}
 	}
 
-	target.DamageModifier += attackMod
-	target.HealthModifier += healthMod
-	target.CurrentHealth += healthMod // Increase current health too
+	duration := DurationPermanent
+	if len(args) >= 4 {
+		duration, err = parseDuration(strings.ToLower(strings.TrimSpace(args[3])))
+		if err != nil {
+			return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
+		}
+	}
+
+	data := map[string]interface{}{
+		"targetInstanceId": target.InstanceID,
+		"attackMod":        attackMod,
+		"healthMod":        healthMod,
+		"duration":         duration,
+	}
+
+	switch duration {
+	case DurationPermanent:
+		target.DamageModifier += attackMod
+		target.HealthModifier += healthMod
+		target.CurrentHealth += healthMod // Increase current health too
+	default:
+		effect := &Effect{
+			TargetInstanceID: target.InstanceID,
+			Controller:       ctx.CasterUID,
+			AttackMod:        attackMod,
+			HealthMod:        healthMod,
+			Duration:         duration,
+		}
+		if duration == DurationTurns {
+			if len(args) < 5 {
+				return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Buff with 'turns' duration requires a turn count"}}}
+			}
+			turns, err := strconv.Atoi(args[4])
+			if err != nil || turns < 1 {
+				return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid turn count: " + args[4]}}}
+			}
+			effect.TurnsRemaining = turns
+			data["turns"] = turns
+		}
+		if duration == DurationWhileSource {
+			if ctx.Card == nil {
+				return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "Buff with 'while_source' duration requires a source card"}}}
+			}
+			effect.SourceInstanceID = ctx.Card.InstanceID
+		}
+		ctx.Game.addEffect(effect)
+		data["effectId"] = effect.ID
+	}
+
+	data["newAttack"] = target.GetAttack()
+	data["newMaxHealth"] = target.GetMaxHealth()
+	data["newHealth"] = target.CurrentHealth
 
 	return []Event{{
 		Type: "ScriptBuff",
-		Data: map[string]interface{}{
-			"targetInstanceId": target.InstanceID,
-			"attackMod":        attackMod,
-			"healthMod":        healthMod,
-			"newAttack":        target.GetAttack(),
-			"newMaxHealth":     target.GetMaxHealth(),
-			"newHealth":        target.CurrentHealth,
-		},
+		Data: data,
 	}}
 }
 
@@ -449,43 +545,40 @@ func scriptGainMana(args []string, ctx *ScriptContext) []Event {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid amount: " + args[1]}}}
 	}
 
-	player, playerUID, err := resolvePlayer(args[2], ctx)
-	if err != nil {
-		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
-	}
-
 	added := ManaCost{}
 	switch color {
 	case "white", "w":
-		player.ManaPool.White += amount
 		added.White = amount
 	case "blue", "u":
-		player.ManaPool.Blue += amount
 		added.Blue = amount
 	case "black", "b":
-		player.ManaPool.Black += amount
 		added.Black = amount
 	case "red", "r":
-		player.ManaPool.Red += amount
 		added.Red = amount
 	case "green", "g":
-		player.ManaPool.Green += amount
 		added.Green = amount
 	case "colorless", "c":
-		player.ManaPool.Colorless += amount
 		added.Colorless = amount
 	default:
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid color: " + color}}}
 	}
 
-	return []Event{{
-		Type: "ScriptManaAdded",
-		Data: map[string]interface{}{
-			"player":   playerUID,
-			"added":    added,
-			"manaPool": player.ManaPool,
-		},
-	}}
+	return forEachPlayer(args[2], ctx, func(player *Player, playerUID string) []Event {
+		player.ManaPool.White += added.White
+		player.ManaPool.Blue += added.Blue
+		player.ManaPool.Black += added.Black
+		player.ManaPool.Red += added.Red
+		player.ManaPool.Green += added.Green
+		player.ManaPool.Colorless += added.Colorless
+		return []Event{{
+			Type: "ScriptManaAdded",
+			Data: map[string]interface{}{
+				"player":   playerUID,
+				"added":    added,
+				"manaPool": player.ManaPool,
+			},
+		}}
+	})
 }
 
 // scriptDiscard: Discard(count, target)
@@ -500,28 +593,25 @@ func scriptDiscard(args []string, ctx *ScriptContext) []Event {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid count: " + args[0]}}}
 	}
 
-	player, playerUID, err := resolvePlayer(args[1], ctx)
-	if err != nil {
-		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
-	}
-
-	discarded := []int{}
-	for i := 0; i < count && len(player.Hand) > 0; i++ {
-		// Discard last card (could randomize)
-		cardID := player.Hand[len(player.Hand)-1]
-		player.Hand = player.Hand[:len(player.Hand)-1]
-		player.Discard = append(player.Discard, cardID)
-		discarded = append(discarded, cardID)
-	}
+	return forEachPlayer(args[1], ctx, func(player *Player, playerUID string) []Event {
+		discarded := []int{}
+		for i := 0; i < count && len(player.Hand) > 0; i++ {
+			// Discard last card (could randomize)
+			cardID := player.Hand[len(player.Hand)-1]
+			player.Hand = player.Hand[:len(player.Hand)-1]
+			player.Discard = append(player.Discard, cardID)
+			discarded = append(discarded, cardID)
+		}
 
-	return []Event{{
-		Type: "ScriptDiscard",
-		Data: map[string]interface{}{
-			"player":    playerUID,
-			"discarded": discarded,
-			"handSize":  len(player.Hand),
-		},
-	}}
+		return []Event{{
+			Type: "ScriptDiscard",
+			Data: map[string]interface{}{
+				"player":    playerUID,
+				"discarded": discarded,
+				"handSize":  len(player.Hand),
+			},
+		}}
+	})
 }
 
 // scriptDestroy: Destroy(target)
@@ -612,17 +702,17 @@ func scriptDamageCreature(args []string, ctx *ScriptContext) []Event {
 		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid target creature: " + targetRef}}}
 	}
 
-	target.CurrentHealth -= amount
+	dealt, statusEvents := target.applyDamage(amount)
 
-	return []Event{{
+	return append([]Event{{
 		Type: "ScriptDamage",
 		Data: map[string]interface{}{
 			"targetType":       "creature",
 			"targetInstanceId": target.InstanceID,
-			"amount":           amount,
+			"amount":           dealt,
 			"newHealth":        target.CurrentHealth,
 		},
-	}}
+	}}, statusEvents...)
 }
 
 // scriptTapCreature: TapCreature(target)
@@ -718,15 +808,102 @@ func scriptBounce(args []string, ctx *ScriptContext) []Event {
 	}
 	owner.Field = newField
 
-	// Add card back to hand
-	owner.Hand = append(owner.Hand, target.CardID)
-
-	return []Event{{
+	events := []Event{{
 		Type: "ScriptBounce",
 		Data: map[string]interface{}{
 			"targetInstanceId": target.InstanceID,
 			"cardId":           target.CardID,
 			"owner":            ownerUID,
+			"toLeaderZone":     target.IsLeader,
 		},
 	}}
+
+	// Add card back to hand (leaders return to the leader zone without tax)
+	if target.IsLeader {
+		events = append(events, ctx.Game.returnLeader(owner, ownerUID, target, false))
+	} else {
+		owner.Hand = append(owner.Hand, target.CardID)
+	}
+
+	return events
+}
+
+// ============================================================================
+// STATUS FUNCTIONS
+// ============================================================================
+
+// resolveCreature resolves a creature reference: "target", "self" or an instance ID
+func resolveCreature(ref string, ctx *ScriptContext) (*FieldCard, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+
+	if ref == "target" && ctx.Target != nil {
+		return ctx.Target, nil
+	}
+	if ref == "self" && ctx.Card != nil {
+		return ctx.Card, nil
+	}
+	if instanceID, err := strconv.Atoi(ref); err == nil {
+		if fc, _ := ctx.Game.findFieldCard(instanceID); fc != nil {
+			return fc, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid target creature: %s", ref)
+}
+
+// scriptApplyStatus: ApplyStatus(status, stacks, target, turns)
+// status: "Stunned", "Poisoned", "Shielded", "Frozen", "Silenced"
+// turns (optional): number of the controller's turns it lasts, omitted = until removed
+func scriptApplyStatus(args []string, ctx *ScriptContext) []Event {
+	if len(args) < 3 {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "ApplyStatus requires 3 arguments: status, stacks, target"}}}
+	}
+
+	status, ok := normalizeStatus(args[0])
+	if !ok {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "unknown status: " + args[0]}}}
+	}
+
+	stacks, err := strconv.Atoi(args[1])
+	if err != nil || stacks < 1 {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid stacks: " + args[1]}}}
+	}
+
+	target, err := resolveCreature(args[2], ctx)
+	if err != nil {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
+	}
+
+	turns := 0
+	if len(args) >= 4 {
+		turns, err = strconv.Atoi(args[3])
+		if err != nil || turns < 0 {
+			return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "invalid turns: " + args[3]}}}
+		}
+	}
+
+	return []Event{target.applyStatus(status, stacks, turns)}
+}
+
+// scriptRemoveStatus: RemoveStatus(status, target)
+// Removes all stacks of a status from a creature
+func scriptRemoveStatus(args []string, ctx *ScriptContext) []Event {
+	if len(args) < 2 {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "RemoveStatus requires 2 arguments: status, target"}}}
+	}
+
+	status, ok := normalizeStatus(args[0])
+	if !ok {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": "unknown status: " + args[0]}}}
+	}
+
+	target, err := resolveCreature(args[1], ctx)
+	if err != nil {
+		return []Event{{Type: "ScriptError", Data: map[string]interface{}{"error": err.Error()}}}
+	}
+
+	if !target.HasStatus(status) {
+		return []Event{}
+	}
+
+	return []Event{target.clearStatus(status, "removed")}
 }
@@ -0,0 +1,181 @@
+// seats.go - Turn order for games with two or more players: whose turn is next,
+// who is still in the game, and passing priority around the table
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxSeats is the most players a free-for-all game can have
+const MaxSeats = 6
+
+// Seats returns how many players the game waits for before it starts
+func (g *Game) Seats() int {
+	if g.MaxPlayers < 2 {
+		return 2
+	}
+	return g.MaxPlayers
+}
+
+// checkSeats validates the player count asked for when creating a game
+func checkSeats(n int) error {
+	if n != 0 && (n < 2 || n > MaxSeats) {
+		return fmt.Errorf("games have 2 to %d players, not %d", MaxSeats, n)
+	}
+	return nil
+}
+
+// addSeat puts a player who joined at the end of the turn order
+func (g *Game) addSeat(uid string) {
+	g.TurnOrder = append(g.TurnOrder, uid)
+}
+
+// removeSeat takes a player who left the lobby out of the turn order
+func (g *Game) removeSeat(uid string) {
+	for i, seat := range g.TurnOrder {
+		if seat == uid {
+			g.TurnOrder = append(g.TurnOrder[:i], g.TurnOrder[i+1:]...)
+			return
+		}
+	}
+}
+
+// seatOrder returns every player in turn order, eliminated or not
+// Games built without a turn order fall back to UID order.
+func (g *Game) seatOrder() []string {
+	if len(g.TurnOrder) == len(g.Players) {
+		return g.TurnOrder
+	}
+	seats := make([]string, 0, len(g.Players))
+	for uid := range g.Players {
+		seats = append(seats, uid)
+	}
+	sort.Strings(seats)
+	return seats
+}
+
+// alivePlayers returns the players still in the game, in turn order
+func (g *Game) alivePlayers() []string {
+	alive := []string{}
+	for _, uid := range g.seatOrder() {
+		if p := g.Players[uid]; p != nil && !p.Eliminated {
+			alive = append(alive, uid)
+		}
+	}
+	return alive
+}
+
+// nextAlive returns the next player after uid in turn order who is still in the game
+// uid itself may already be eliminated; it's returned only if nobody else is left.
+func (g *Game) nextAlive(uid string) string {
+	seats := g.seatOrder()
+	start := 0
+	for i, seat := range seats {
+		if seat == uid {
+			start = i
+			break
+		}
+	}
+	for n := 1; n <= len(seats); n++ {
+		seat := seats[(start+n)%len(seats)]
+		if p := g.Players[seat]; p != nil && !p.Eliminated {
+			return seat
+		}
+	}
+	return uid
+}
+
+// isOpponent reports whether other is an opponent of uid who is still in the game
+// In team games a teammate is never an opponent.
+func (g *Game) isOpponent(uid, other string) bool {
+	p, ok := g.Players[other]
+	return ok && g.sideOf(other) != g.sideOf(uid) && !p.Eliminated
+}
+
+// opponentsOf returns uid's opponents still in the game, starting with the next player in turn order
+func (g *Game) opponentsOf(uid string) []string {
+	seats := g.seatOrder()
+	start := 0
+	for i, seat := range seats {
+		if seat == uid {
+			start = i
+			break
+		}
+	}
+	opponents := []string{}
+	for n := 1; n < len(seats); n++ {
+		if seat := seats[(start+n)%len(seats)]; g.isOpponent(uid, seat) {
+			opponents = append(opponents, seat)
+		}
+	}
+	return opponents
+}
+
+// passPriorityOn gives priority to the next player around the table in the response window
+func (g *Game) passPriorityOn(fromUID string) Event {
+	g.PriorityPlayer = g.nextAlive(fromUID)
+	return Event{
+		Type: "PriorityChanged",
+		Data: map[string]interface{}{
+			"priorityPlayer": g.PriorityPlayer,
+		},
+	}
+}
+
+// allPassed reports whether every player still in the game passed priority in a row
+func (g *Game) allPassed() bool {
+	for _, uid := range g.alivePlayers() {
+		if !g.PassedPlayers[uid] {
+			return false
+		}
+	}
+	return true
+}
+
+// eliminate takes a player out of a game that carries on without them
+// Their permanents leave the battlefield, attacks on them are called off and,
+// if it was their turn or their priority, play moves on to the next player.
+func (g *Game) eliminate(uid, reason string) []Event {
+	p := g.Players[uid]
+	p.Eliminated = true
+	for _, fc := range p.Field {
+		if !fc.IsLeader {
+			p.Discard = append(p.Discard, fc.CardID)
+		}
+	}
+	p.Field = []*FieldCard{}
+
+	events := []Event{{
+		Type: "PlayerEliminated",
+		Data: map[string]interface{}{
+			"player":    uid,
+			"reason":    reason,
+			"remaining": g.alivePlayers(),
+		},
+	}}
+	events = append(events, g.pruneEffects()...)
+
+	if g.CombatPhase != "" {
+		if g.AttackingPlayer == uid {
+			g.clearCombat()
+			events = append(events, Event{Type: "CombatEnded", Data: map[string]interface{}{}})
+		} else {
+			attacks := []PendingAttack{}
+			for _, pa := range g.PendingAttacks {
+				if pa.TargetPlayerUID != uid {
+					attacks = append(attacks, pa)
+				}
+			}
+			g.PendingAttacks = attacks
+			if g.PriorityPlayer == uid {
+				events = append(events, g.passPriorityOn(uid))
+			}
+		}
+	}
+
+	if g.Turn == uid {
+		events = append(events, g.endTurn(Action{PlayerUID: uid})...)
+	}
+	return events
+}
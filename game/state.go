@@ -1,14 +1,19 @@
 package game
 
 import (
+    "context"
     "fmt"
-    "log"
+    "log/slog"
     "math/rand"
     "sync"
     "time"
 )
 
 type Game struct {
+    // Serializes changes to the game: each connection, the turn timer and the cleanup
+    // routine run on their own goroutines. Taken after GameManager.mu, before Match.mu.
+    mu sync.Mutex
+
     ID             string
     Players        map[string]*Player // keyed by player UID
     Turn           string             // UID of whose turn it is
@@ -16,6 +21,41 @@ type Game struct {
     Winner         string             // UID of winner, empty if game ongoing
     NextInstanceID int                // Counter for unique field card IDs
 
+    // Seating (see seats.go)
+    MaxPlayers int      // Players the game waits for (0 = 2)
+    TurnOrder  []string // Player UIDs in turn order, in the order they joined
+
+    // Two-versus-two teams (see teams.go)
+    TeamGame   bool           // Players are split into two teams when the game starts
+    SharedLife bool           // Teammates share one life total
+    Teams      map[string]int // Player UID -> team (0 or 1), set when the game starts
+
+    // Rules this game is played with (see rulesets.go)
+    Rules         RuleSet
+    TurnStartedAt time.Time // For the rule set's turn timer
+
+    // Match tracking (for history and profiles)
+    TurnNumber int       // Turns taken so far, starting at 1 when the game starts
+    StartedAt  time.Time // When the mulligan phase ended
+    EndedAt    time.Time // When a winner was decided
+    EndReason  string    // Why the game ended: "life" or "forfeit"
+    Ranked     bool      // Result counts towards ratings
+
+    // History of a game (and match) that just ended, written once g.mu is released (see runLocked)
+    unrecorded       *MatchRecord
+    unrecordedResult *MatchResult
+
+    // Lobby settings
+    Host         string // UID of the player who created the game
+    InviteCode   string // Set for private games, which are hidden from the game list
+    passwordHash []byte // Set for password-locked games
+    kicked       map[string]bool // Players the host kicked, who can't rejoin
+
+    // Best-of-N match this game is part of (empty for a single game)
+    MatchID    string
+    GameNumber int
+    match      *Match
+
     // Mulligan state
     MulliganPhase     bool            // true while waiting for mulligan decisions
     MulliganDecisions map[string]bool // tracks each player's decision (true = decided)
@@ -32,9 +72,16 @@ type Game struct {
     PriorityPlayer string          // UID of player who has priority to play instants
     PassedPlayers  map[string]bool // Tracks which players passed priority consecutively
 
+    // Duration-based effects (temporary buffs)
+    Effects      []*Effect // Active effects, expired in endTurn
+    NextEffectID int       // Counter for unique effect IDs
+
     // Cleanup tracking
     LastActivity time.Time           // Updated on every action
     Disconnects  map[string]time.Time // playerUID -> disconnect time
+
+    // Card data version this game started with (kept across reloads)
+    Pool *CardPool
 }
 
 // PendingAttack represents an attack waiting for blocker assignment
@@ -48,7 +95,7 @@ type PendingAttack struct {
 
 // NewFieldCard creates a new card on the battlefield
 func (g *Game) NewFieldCard(cardID int, owner, castedBy string) *FieldCard {
-    card := CardDB[cardID]
+    card := g.Card(cardID)
     fc := &FieldCard{
         InstanceID:     g.NextInstanceID,
         CardID:         cardID,
@@ -59,6 +106,7 @@ func (g *Game) NewFieldCard(cardID int, owner, castedBy string) *FieldCard {
         CurrentHealth:  card.Defense,
         CanAttack:      false, // Summoning sickness by default
         Status:         make(map[string]int),
+        pool:           g.Pool,
     }
     g.NextInstanceID++
 
@@ -77,6 +125,18 @@ func (g *Game) NewFieldCard(cardID int, owner, castedBy string) *FieldCard {
     return fc
 }
 
+// findFieldCard finds a card on any player's field by instance ID
+func (g *Game) findFieldCard(instanceID int) (*FieldCard, string) {
+    for uid, p := range g.Players {
+        for _, fc := range p.Field {
+            if fc.InstanceID == instanceID {
+                return fc, uid
+            }
+        }
+    }
+    return nil, ""
+}
+
 type Player struct {
     UID                 string
     Hand                []int
@@ -86,26 +146,30 @@ type Player struct {
     Field               []*FieldCard // Cards on the battlefield
     Life                int
     DeckID              int          // Deck ID
-    Leader              int          // Leader card ID
+    DeckName            string       // Deck name (kept for match history)
+    Leader              int          // Leader card ID in the leader zone (0 while on the field)
+    LeaderCardID        int          // Leader card ID, kept while the leader is on the field
+    LeaderDeaths        int          // Times the leader has died (drives the recast tax)
+    LeaderPowerUsed     bool         // Whether the hero power was used this turn
     ManaPool            ManaCost     // Current mana available to spend
     LandsPerTurn        int          // Max lands that can be played per turn (default 1)
     LandsPlayedThisTurn int          // Lands played this turn
     MinHandLimit        int          // Minimum hand size to draw up to (default 1)
+    Eliminated          bool         // Knocked out of a game with more than two players
+
+    pool *CardPool // Card data of the player's game
 }
 
-// GetAvailableMana counts the total mana available from lands on the field
-func (p *Player) GetAvailableMana() ManaCost {
-    available := ManaCost{}
+// GetAvailableMana lists the mana each untapped land on the field can produce
+// Each entry is one land's choice set: tapping it produces exactly one of the options
+func (p *Player) GetAvailableMana() [][]ManaCost {
+    available := [][]ManaCost{}
     for _, fc := range p.Field {
-        card := CardDB[fc.CardID]
-        if card.CardType == "Land" {
-            provided := card.GetProvidedMana()
-            available.White += provided.White
-            available.Blue += provided.Blue
-            available.Black += provided.Black
-            available.Red += provided.Red
-            available.Green += provided.Green
-            available.Colorless += provided.Colorless
+        card := p.card(fc.CardID)
+        if card.CardType == "Land" && !fc.IsTapped() {
+            if options := card.ManaOptions(); len(options) > 0 {
+                available = append(available, options)
+            }
         }
     }
     return available
@@ -119,9 +183,15 @@ type FieldCard struct {
     CastedBy       string         `json:"castedBy"`       // UID of player who played the card
     DamageModifier int            `json:"damageModifier"` // +/- to attack
     HealthModifier int            `json:"healthModifier"` // +/- to defense/health
+    EffectAttack   int            `json:"effectAttack"`   // Attack from active duration effects
+    EffectHealth   int            `json:"effectHealth"`   // Health from active duration effects
     CurrentHealth  int            `json:"currentHealth"`  // Current health (starts at card's Defense)
     CanAttack      bool           `json:"canAttack"`      // Whether it can attack this turn (summoning sickness)
-    Status         map[string]int `json:"status"`         // Status values (Tapped=1, etc.)
+    IsLeader       bool           `json:"isLeader"`       // Whether this is its owner's leader
+    Status         map[string]int `json:"status"`         // Status stacks (Tapped, Summoned, Stunned, Poisoned, etc.)
+    StatusTurns    map[string]int `json:"statusTurns"`    // Turns remaining for timed statuses
+
+    pool *CardPool // Card data of the game it's in
 }
 
 // IsTapped returns whether the card is tapped
@@ -154,14 +224,14 @@ func (fc *FieldCard) IsSummoned() bool {
 
 // GetAttack returns the effective attack value
 func (fc *FieldCard) GetAttack() int {
-    card := CardDB[fc.CardID]
-    return card.Attack + fc.DamageModifier
+    card := fc.card()
+    return card.Attack + fc.DamageModifier + fc.EffectAttack
 }
 
 // GetMaxHealth returns the effective max health
 func (fc *FieldCard) GetMaxHealth() int {
-    card := CardDB[fc.CardID]
-    return card.Defense + fc.HealthModifier
+    card := fc.card()
+    return card.Defense + fc.HealthModifier + fc.EffectHealth
 }
 
 // IsDead returns true if the card should be removed from field
@@ -169,14 +239,8 @@ func (fc *FieldCard) IsDead() bool {
     return fc.CurrentHealth <= 0
 }
 
-const InitialMainDeckDraw = 5
-const InitialVaultDraw = 2
-const DefaultMinHandLimit = 1
-const DefaultLandsPerTurn = 1
-const DefaultLife = 30
-
-// NewPlayer creates a new player with a deck
-func NewPlayer(uid string, deck Deck) *Player {
+// NewPlayer creates a new player with a deck from the given card pool, set up by a game's rules
+func NewPlayer(uid string, deck Deck, pool *CardPool, rules *RuleSet) *Player {
     return &Player{
         UID:          uid,
         Hand:         []int{},
@@ -184,11 +248,14 @@ func NewPlayer(uid string, deck Deck) *Player {
         VaultPile:    ShuffleDeck(deck.Vault),
         Discard:      []int{},
         Field:        []*FieldCard{},
-        Life:         DefaultLife,
+        Life:         rules.Life,
         DeckID:       deck.ID,
+        DeckName:     deck.Name,
         Leader:       deck.Leader,
-        LandsPerTurn: DefaultLandsPerTurn,
-        MinHandLimit: DefaultMinHandLimit,
+        LeaderCardID: deck.Leader,
+        LandsPerTurn: rules.LandsPerTurn,
+        MinHandLimit: rules.MinHandLimit,
+        pool:         pool,
     }
 }
 
@@ -228,30 +295,78 @@ func (p *Player) DrawFromVault(n int) []int {
 
 // GameManager handles multiple concurrent games
 type GameManager struct {
-    mu       sync.RWMutex
-    games    map[string]*Game // gameID -> Game
-    waiting  *Game            // game waiting for second player
-    nextID   int
+    mu      sync.RWMutex
+    games   map[string]*Game  // gameID -> Game
+    matches map[string]*Match // matchID -> best-of-N match
+    nextID  int
+
+    draining bool // No new games while the server waits to restart (see drain.go)
+
+    cfg ManagerConfig
 }
 
-var Manager = &GameManager{
-    games:  make(map[string]*Game),
-    nextID: 1,
+// ManagerConfig holds the timeouts stale games are cleaned up with
+type ManagerConfig struct {
+    DisconnectTimeout time.Duration // Remove a game once every player has been disconnected this long
+    InactivityTimeout time.Duration // Remove a started game after this long without an action
+    CleanupInterval   time.Duration // How often to check
 }
 
-func (gm *GameManager) CreateGame(playerUID string, deckID int) (*Game, *Player, error) {
+// DefaultManagerConfig is used until the server passes in its own
+var DefaultManagerConfig = ManagerConfig{
+    DisconnectTimeout: 1 * time.Minute,
+    InactivityTimeout: 5 * time.Minute,
+    CleanupInterval:   30 * time.Second,
+}
+
+// Manager is the game manager used by the server (replaced at startup with the configured one)
+var Manager = NewGameManager(DefaultManagerConfig)
+
+// NewGameManager creates a game manager with no games
+func NewGameManager(cfg ManagerConfig) *GameManager {
+    return &GameManager{
+        games:  make(map[string]*Game),
+        nextID: 1,
+        cfg:    cfg,
+    }
+}
+
+func (gm *GameManager) CreateGame(playerUID string, deckID int, opts GameOptions) (*Game, *Player, error) {
     gm.mu.Lock()
     defer gm.mu.Unlock()
 
-    deck, ok := DeckDB[deckID]
-    if !ok {
-        return nil, nil, fmt.Errorf("deck not found: %d", deckID)
+    if gm.draining {
+        return nil, nil, errDraining
+    }
+    if opts.BestOf > 1 && !MatchLengths[opts.BestOf] {
+        return nil, nil, fmt.Errorf("matches are best of 3 or 5, not %d", opts.BestOf)
+    }
+    if err := checkTeams(opts); err != nil {
+        return nil, nil, err
+    }
+    if opts.Teams {
+        opts.MaxPlayers = TeamSeats
+    }
+    if err := checkSeats(opts.MaxPlayers); err != nil {
+        return nil, nil, err
+    }
+    if opts.MaxPlayers > 2 && (opts.Ranked || opts.BestOf > 1) {
+        return nil, nil, fmt.Errorf("ranked games and matches are one-on-one")
+    }
+    rules, err := FindRuleSet(opts.RuleSet)
+    if err != nil {
+        return nil, nil, err
+    }
+    pool := CurrentPool()
+    deck, err := findDeck(pool, &rules, playerUID, deckID)
+    if err != nil {
+        return nil, nil, err
     }
 
     gameID := fmt.Sprintf("game_%d", gm.nextID)
     gm.nextID++
 
-    player := NewPlayer(playerUID, deck)
+    player := NewPlayer(playerUID, deck, pool, &rules)
 
     g := &Game{
         ID:             gameID,
@@ -259,47 +374,75 @@ func (gm *GameManager) CreateGame(playerUID string, deckID int) (*Game, *Player,
         Turn:           playerUID,
         Started:        false,
         NextInstanceID: 1,
+        Pool:           pool,
+        Rules:          rules,
+        Ranked:         opts.Ranked,
+        Host:           playerUID,
+        MaxPlayers:     opts.MaxPlayers,
+        TurnOrder:      []string{playerUID},
+        TeamGame:       opts.Teams,
+        SharedLife:     opts.SharedLife,
+    }
+    if opts.Private {
+        g.InviteCode = gm.newInviteCode()
+    } else if opts.Password != "" {
+        g.passwordHash = hashPassword(gameID, opts.Password)
+    }
+    if opts.BestOf > 1 {
+        gm.newMatch(g, opts.BestOf, deck)
     }
 
     gm.games[gameID] = g
-    gm.waiting = g
 
     return g, player, nil
 }
 
-func (gm *GameManager) JoinGame(playerUID string, deckID int) (*Game, *Player, error) {
+// CreateMatch starts a game between players paired by the matchmaker
+// A random player goes first; the game begins in the mulligan phase.
+func (gm *GameManager) CreateMatch(entries []*QueueEntry, ranked bool) (*Game, error) {
     gm.mu.Lock()
     defer gm.mu.Unlock()
 
-    if gm.waiting == nil {
-        return nil, nil, fmt.Errorf("no game available")
+    if gm.draining {
+        return nil, errDraining
     }
-
-    deck, ok := DeckDB[deckID]
-    if !ok {
-        return nil, nil, fmt.Errorf("deck not found: %d", deckID)
+    pool := CurrentPool()
+    players := map[string]*Player{}
+    order := []string{}
+    for _, e := range entries {
+        // Someone who joined another game after being paired can't play this one
+        if other := gm.playerGame(e.PlayerUID); other != nil {
+            return nil, fmt.Errorf("%s is already in game %s", e.PlayerUID, other.ID)
+        }
+        // Decks are checked again here since a custom deck can change while queued
+        deck, err := findDeck(pool, &DefaultRules, e.PlayerUID, e.DeckID)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %v", e.PlayerUID, err)
+        }
+        players[e.PlayerUID] = NewPlayer(e.PlayerUID, deck, pool, &DefaultRules)
+        order = append(order, e.PlayerUID)
     }
 
-    g := gm.waiting
+    gameID := fmt.Sprintf("game_%d", gm.nextID)
+    gm.nextID++
 
-    // Don't let same player join twice
-    if _, exists := g.Players[playerUID]; exists {
-        return nil, nil, fmt.Errorf("already in this game")
+    g := &Game{
+        ID:                gameID,
+        Players:           players,
+        TurnOrder:         order,
+        Turn:              entries[rand.Intn(len(entries))].PlayerUID,
+        NextInstanceID:    1,
+        Pool:              pool,
+        Rules:             DefaultRules,
+        Ranked:            ranked,
+        Host:              order[0],
+        MulliganPhase:     true,
+        MulliganDecisions: make(map[string]bool),
     }
-
-    player := NewPlayer(playerUID, deck)
-
-    g.Players[playerUID] = player
-    gm.waiting = nil // game is full
-
-    // Draw initial hands for both players
     g.DrawInitialHands()
 
-    // Start mulligan phase (game starts after both players decide)
-    g.MulliganPhase = true
-    g.MulliganDecisions = make(map[string]bool)
-
-    return g, player, nil
+    gm.games[gameID] = g
+    return g, nil
 }
 
 func (gm *GameManager) GetGame(gameID string) *Game {
@@ -308,65 +451,128 @@ func (gm *GameManager) GetGame(gameID string) *Game {
     return gm.games[gameID]
 }
 
+// PlayerGame returns the game or lobby a player is still playing in, if any
+func (gm *GameManager) PlayerGame(playerUID string) *Game {
+    gm.mu.RLock()
+    defer gm.mu.RUnlock()
+    return gm.playerGame(playerUID)
+}
+
+// playerGame finds a game the player is seated in and not out of: not over, or
+// between games of a match (caller holds gm.mu)
+func (gm *GameManager) playerGame(playerUID string) *Game {
+    for _, g := range gm.games {
+        g.mu.Lock()
+        p, seated := g.Players[playerUID]
+        playing := seated && !p.Eliminated && (g.Winner == "" || g.matchOngoing())
+        g.mu.Unlock()
+        if playing {
+            return g
+        }
+    }
+    return nil
+}
+
 // GameInfo for lobby display
 type GameInfo struct {
     GameID      string   `json:"gameId"`
     PlayerCount int      `json:"playerCount"`
     Players     []string `json:"players"`
     Started     bool     `json:"started"`
+    Ranked      bool     `json:"ranked"`
+    Locked      bool     `json:"locked"` // Needs a password to join
+    MaxPlayers  int      `json:"maxPlayers"`
+    Teams       bool     `json:"teams"`
+    RuleSet     string   `json:"ruleSet"`
 }
 
-// ListGames returns all games for the lobby
+// ListGames returns the games shown in the lobby
+// Private games are left out, and password-locked games don't list their players.
 func (gm *GameManager) ListGames() []GameInfo {
     gm.mu.RLock()
     defer gm.mu.RUnlock()
 
     games := []GameInfo{}
     for _, g := range gm.games {
+        if g.InviteCode != "" {
+            continue
+        }
+        g.mu.Lock()
         players := []string{}
-        for uid := range g.Players {
-            players = append(players, uid)
+        if !g.IsLocked() {
+            for uid := range g.Players {
+                players = append(players, uid)
+            }
         }
         games = append(games, GameInfo{
             GameID:      g.ID,
             PlayerCount: len(g.Players),
             Players:     players,
             Started:     g.Started,
+            Ranked:      g.Ranked,
+            Locked:      g.IsLocked(),
+            MaxPlayers:  g.Seats(),
+            Teams:       g.TeamGame,
+            RuleSet:     g.Rules.Name,
         })
+        g.mu.Unlock()
     }
     return games
 }
 
-// JoinSpecificGame joins a specific game by ID
-func (gm *GameManager) JoinSpecificGame(gameID, playerUID string, deckID int) (*Game, *Player, error) {
+// JoinSpecificGame joins a specific game by ID, or a private game by its invite code
+// Locked games need the invite code or password as secret. They wait for the
+// host to start them instead of going straight to the mulligan phase; other
+// games start once every seat is taken.
+func (gm *GameManager) JoinSpecificGame(gameID, playerUID string, deckID int, secret string) (*Game, *Player, error) {
     gm.mu.Lock()
     defer gm.mu.Unlock()
 
     g, exists := gm.games[gameID]
+    if gameID == "" && secret != "" {
+        g = gm.findByInviteCode(secret)
+        exists = g != nil
+    }
     if !exists {
         return nil, nil, fmt.Errorf("game not found")
     }
-    if g.Started || g.MulliganPhase || len(g.Players) >= 2 {
+    if gm.draining {
+        return nil, nil, errDraining
+    }
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if err := g.checkSecret(secret); err != nil {
+        return nil, nil, err
+    }
+    if g.kicked[playerUID] {
+        return nil, nil, fmt.Errorf("the host removed you from this game")
+    }
+    if g.Started || g.MulliganPhase || len(g.Players) >= g.Seats() {
         return nil, nil, fmt.Errorf("game is full")
     }
     if _, exists := g.Players[playerUID]; exists {
         return nil, nil, fmt.Errorf("already in this game")
     }
 
-    deck, ok := DeckDB[deckID]
-    if !ok {
-        return nil, nil, fmt.Errorf("deck not found")
+    deck, err := findDeck(g.Pool, &g.Rules, playerUID, deckID)
+    if err != nil {
+        return nil, nil, err
     }
 
-    player := NewPlayer(playerUID, deck)
+    player := NewPlayer(playerUID, deck, g.Pool, &g.Rules)
 
     g.Players[playerUID] = player
-    if gm.waiting == g {
-        gm.waiting = nil
+    g.addSeat(playerUID)
+    if g.match != nil {
+        g.match.addPlayer(playerUID, deck)
     }
+    if g.IsLocked() || len(g.Players) < g.Seats() {
+        return g, player, nil
+    }
+    g.assignTeams()
     g.DrawInitialHands()
 
-    // Start mulligan phase (game starts after both players decide)
+    // Start mulligan phase (game starts after every player decides)
     g.MulliganPhase = true
     g.MulliganDecisions = make(map[string]bool)
 
@@ -376,13 +582,15 @@ func (gm *GameManager) JoinSpecificGame(gameID, playerUID string, deckID int) (*
 // DrawInitialHands draws starting hands for all players (5 from main deck, 2 from vault)
 func (g *Game) DrawInitialHands() {
     for _, player := range g.Players {
-        player.DrawCards(InitialMainDeckDraw)
-        player.DrawFromVault(InitialVaultDraw)
+        player.DrawCards(g.Rules.InitialMainDeckDraw)
+        player.DrawFromVault(g.Rules.InitialVaultDraw)
     }
 }
 
 // MarkPlayerDisconnected records when a player disconnected
 func (g *Game) MarkPlayerDisconnected(playerUID string) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
     if g.Disconnects == nil {
         g.Disconnects = make(map[string]time.Time)
     }
@@ -391,6 +599,8 @@ func (g *Game) MarkPlayerDisconnected(playerUID string) {
 
 // MarkPlayerReconnected clears disconnect status when player reconnects
 func (g *Game) MarkPlayerReconnected(playerUID string) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
     if g.Disconnects != nil {
         delete(g.Disconnects, playerUID)
     }
@@ -398,7 +608,9 @@ func (g *Game) MarkPlayerReconnected(playerUID string) {
 
 // AllPlayersDisconnectedFor checks if all players have been disconnected for the given duration
 func (g *Game) AllPlayersDisconnectedFor(duration time.Duration) bool {
-    if g.Disconnects == nil || len(g.Disconnects) < len(g.Players) {
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    if len(g.Players) == 0 || g.Disconnects == nil || len(g.Disconnects) < len(g.Players) {
         return false
     }
     cutoff := time.Now().Add(-duration)
@@ -410,15 +622,26 @@ func (g *Game) AllPlayersDisconnectedFor(duration time.Duration) bool {
     return true
 }
 
-// RemoveGame removes a game from the manager
+// Progress reports whether the game has started, whether it's in the mulligan phase, and who won it
+// A nil game reports nothing started.
+func (g *Game) Progress() (started, mulliganPhase bool, winner string) {
+    if g == nil {
+        return false, false, ""
+    }
+    g.mu.Lock()
+    defer g.mu.Unlock()
+    return g.Started, g.MulliganPhase, g.Winner
+}
+
+// RemoveGame removes a game from the manager, along with the match it's part of
 func (gm *GameManager) RemoveGame(gameID string) {
     gm.mu.Lock()
     defer gm.mu.Unlock()
-    if gm.waiting != nil && gm.waiting.ID == gameID {
-        gm.waiting = nil
+    if g := gm.games[gameID]; g != nil && g.MatchID != "" {
+        delete(gm.matches, g.MatchID)
     }
     delete(gm.games, gameID)
-    log.Printf("Game %s removed", gameID)
+    slog.Info("game removed", "game", gameID)
 }
 
 // GetAllGameIDs returns all game IDs for cleanup iteration
@@ -432,21 +655,20 @@ func (gm *GameManager) GetAllGameIDs() []string {
     return ids
 }
 
-// Cleanup constants
-const (
-    DisconnectTimeout = 1 * time.Minute  // Kill if both disconnected 1+ min
-    InactivityTimeout = 5 * time.Minute  // Kill if no activity 5+ min
-    CleanupInterval   = 30 * time.Second // Check every 30 seconds
-)
-
-// StartCleanupRoutine starts the background cleanup goroutine
-func (gm *GameManager) StartCleanupRoutine() {
+// StartCleanupRoutine starts the background cleanup goroutine, which runs until ctx is done
+func (gm *GameManager) StartCleanupRoutine(ctx context.Context) {
     go func() {
-        ticker := time.NewTicker(CleanupInterval)
+        ticker := time.NewTicker(gm.cfg.CleanupInterval)
         defer ticker.Stop()
-        log.Println("Game cleanup routine started")
-        for range ticker.C {
-            gm.cleanupStaleGames()
+        slog.Info("game cleanup routine started")
+        for {
+            select {
+            case <-ctx.Done():
+                slog.Info("game cleanup routine stopped")
+                return
+            case <-ticker.C:
+                gm.cleanupStaleGames()
+            }
         }
     }()
 }
@@ -464,20 +686,24 @@ func (gm *GameManager) cleanupStaleGames() {
         shouldRemove := false
         reason := ""
 
-        // Check 1: Both players disconnected for 1+ minute
-        if len(g.Players) > 0 && g.AllPlayersDisconnectedFor(DisconnectTimeout) {
+        // Check 1: Every player disconnected for DisconnectTimeout
+        if g.AllPlayersDisconnectedFor(gm.cfg.DisconnectTimeout) {
             shouldRemove = true
             reason = "both players disconnected"
         }
 
-        // Check 2: No activity for 5+ minutes (only for started games)
-        if g.Started && !g.LastActivity.IsZero() && now.Sub(g.LastActivity) > InactivityTimeout {
+        // Check 2: No activity for InactivityTimeout (only for started games)
+        // A finished game of a match that isn't over waits for sideboarding, which takes no game actions.
+        g.mu.Lock()
+        sideboarding := g.Winner != "" && g.matchOngoing()
+        if g.Started && !sideboarding && !g.LastActivity.IsZero() && now.Sub(g.LastActivity) > gm.cfg.InactivityTimeout {
             shouldRemove = true
             reason = "inactivity timeout"
         }
+        g.mu.Unlock()
 
         if shouldRemove {
-            log.Printf("Removing game %s: %s", gameID, reason)
+            slog.Info("removing stale game", "game", gameID, "reason", reason)
             gm.RemoveGame(gameID)
         }
     }
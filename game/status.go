@@ -0,0 +1,187 @@
+// status.go - Status effects on field cards: stacks, durations and expiry
+package game
+
+import "strings"
+
+// Named statuses that can be applied to field cards
+const (
+	StatusStunned  = "Stunned"  // Cannot attack
+	StatusPoisoned = "Poisoned" // Takes 1 damage per stack at the start of its controller's turn
+	StatusShielded = "Shielded" // Each stack prevents one instance of damage
+	StatusFrozen   = "Frozen"   // Does not untap and cannot attack
+	StatusSilenced = "Silenced" // Loses all keyword abilities
+)
+
+// StatusDef describes how a named status behaves
+type StatusDef struct {
+	Stackable bool // Whether applying it again adds stacks instead of refreshing
+	MaxStacks int  // Upper bound on stacks (0 = unlimited)
+}
+
+var statusDefs = map[string]StatusDef{
+	StatusStunned:  {Stackable: false},
+	StatusPoisoned: {Stackable: true, MaxStacks: 10},
+	StatusShielded: {Stackable: true, MaxStacks: 5},
+	StatusFrozen:   {Stackable: false},
+	StatusSilenced: {Stackable: false},
+}
+
+// normalizeStatus maps a case-insensitive status name to its canonical form
+func normalizeStatus(name string) (string, bool) {
+	for status := range statusDefs {
+		if strings.EqualFold(status, strings.TrimSpace(name)) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// HasStatus returns whether the card currently has the named status
+func (fc *FieldCard) HasStatus(status string) bool {
+	return fc.StatusStacks(status) > 0
+}
+
+// StatusStacks returns the number of stacks of the named status
+func (fc *FieldCard) StatusStacks(status string) int {
+	if fc.Status == nil {
+		return 0
+	}
+	return fc.Status[status]
+}
+
+// HasAbility checks the card's abilities, respecting Silenced
+func (fc *FieldCard) HasAbility(ability string) bool {
+	if fc.HasStatus(StatusSilenced) {
+		return false
+	}
+	return fc.card().HasAbility(ability)
+}
+
+// ActiveAbilities returns the card's abilities that are in effect (none while Silenced)
+func (fc *FieldCard) ActiveAbilities() []string {
+	if fc.HasStatus(StatusSilenced) {
+		return []string{}
+	}
+	return fc.card().Abilities
+}
+
+// applyDamage deals damage to the card, letting a Shielded stack absorb it
+// Returns the damage actually dealt and any status events
+func (fc *FieldCard) applyDamage(amount int) (int, []Event) {
+	if amount <= 0 || !fc.HasStatus(StatusShielded) {
+		fc.CurrentHealth -= amount
+		return amount, nil
+	}
+
+	fc.Status[StatusShielded]--
+	events := []Event{{
+		Type: "ShieldAbsorbed",
+		Data: map[string]interface{}{
+			"instanceId":      fc.InstanceID,
+			"owner":           fc.Owner,
+			"amount":          amount,
+			"stacksRemaining": fc.Status[StatusShielded],
+		},
+	}}
+	if fc.Status[StatusShielded] == 0 {
+		events = append(events, fc.clearStatus(StatusShielded, "absorbed"))
+	}
+	return 0, events
+}
+
+// applyStatus adds stacks of a status to the card
+// turns > 0 limits how many of the controller's turns it lasts, 0 means until removed
+func (fc *FieldCard) applyStatus(status string, stacks, turns int) Event {
+	if fc.Status == nil {
+		fc.Status = make(map[string]int)
+	}
+	if fc.StatusTurns == nil {
+		fc.StatusTurns = make(map[string]int)
+	}
+
+	def := statusDefs[status]
+	if def.Stackable {
+		fc.Status[status] += stacks
+	} else {
+		fc.Status[status] = 1
+	}
+	if def.MaxStacks > 0 && fc.Status[status] > def.MaxStacks {
+		fc.Status[status] = def.MaxStacks
+	}
+
+	if turns > 0 {
+		fc.StatusTurns[status] = turns
+	} else {
+		delete(fc.StatusTurns, status)
+	}
+
+	return Event{
+		Type: "StatusApplied",
+		Data: map[string]interface{}{
+			"instanceId": fc.InstanceID,
+			"owner":      fc.Owner,
+			"status":     status,
+			"stacks":     fc.Status[status],
+			"turns":      turns,
+		},
+	}
+}
+
+// clearStatus removes a status entirely and returns the expiry event
+func (fc *FieldCard) clearStatus(status, reason string) Event {
+	delete(fc.Status, status)
+	if fc.StatusTurns != nil {
+		delete(fc.StatusTurns, status)
+	}
+	return Event{
+		Type: "StatusExpired",
+		Data: map[string]interface{}{
+			"instanceId": fc.InstanceID,
+			"owner":      fc.Owner,
+			"status":     status,
+			"reason":     reason,
+		},
+	}
+}
+
+// tickStatuses counts down timed statuses on the ending player's field
+func (g *Game) tickStatuses(endingPlayer string) []Event {
+	events := []Event{}
+	player := g.Players[endingPlayer]
+	for _, fc := range player.Field {
+		for status, turns := range fc.StatusTurns {
+			if turns <= 0 {
+				continue
+			}
+			fc.StatusTurns[status] = turns - 1
+			if turns-1 == 0 {
+				events = append(events, fc.clearStatus(status, "duration_ended"))
+			}
+		}
+	}
+	return events
+}
+
+// applyStartOfTurnStatuses resolves statuses that trigger as a player's turn begins
+func (g *Game) applyStartOfTurnStatuses(playerUID string) []Event {
+	events := []Event{}
+	player := g.Players[playerUID]
+	for _, fc := range player.Field {
+		stacks := fc.StatusStacks(StatusPoisoned)
+		if stacks == 0 {
+			continue
+		}
+		fc.CurrentHealth -= stacks
+		events = append(events, Event{
+			Type: "StatusDamage",
+			Data: map[string]interface{}{
+				"instanceId": fc.InstanceID,
+				"owner":      playerUID,
+				"status":     StatusPoisoned,
+				"amount":     stacks,
+				"newHealth":  fc.CurrentHealth,
+			},
+		})
+	}
+	return events
+}
@@ -0,0 +1,82 @@
+// teams.go - Two-versus-two games: who is on which team, shared life and team chat
+package game
+
+import "fmt"
+
+// TeamSeats is how many players a team game seats (two teams of two)
+const TeamSeats = 4
+
+// checkTeams validates the team options asked for when creating a game
+func checkTeams(opts GameOptions) error {
+	if !opts.Teams {
+		if opts.SharedLife {
+			return fmt.Errorf("shared life is only for team games")
+		}
+		return nil
+	}
+	if opts.MaxPlayers != 0 && opts.MaxPlayers != TeamSeats {
+		return fmt.Errorf("team games have %d players", TeamSeats)
+	}
+	return nil
+}
+
+// assignTeams splits the players into two teams when the game starts
+// Teams alternate around the table, so turns alternate between them too.
+func (g *Game) assignTeams() {
+	if !g.TeamGame {
+		return
+	}
+	g.Teams = make(map[string]int)
+	for i, uid := range g.TurnOrder {
+		g.Teams[uid] = i % 2
+		if g.SharedLife {
+			g.Players[uid].Life = g.Rules.SharedTeamLife
+		}
+	}
+}
+
+// sideOf returns what a player wins or loses with: their team, or just themselves
+func (g *Game) sideOf(uid string) string {
+	if team, ok := g.Teams[uid]; ok {
+		return fmt.Sprintf("team %d", team+1)
+	}
+	return uid
+}
+
+// teamOf returns every player on uid's side, uid first, eliminated or not
+func (g *Game) teamOf(uid string) []string {
+	team := []string{uid}
+	if g.Teams == nil {
+		return team
+	}
+	for _, seat := range g.seatOrder() {
+		if seat != uid && g.Teams[seat] == g.Teams[uid] {
+			team = append(team, seat)
+		}
+	}
+	return team
+}
+
+// Teammates returns the other players on uid's team (none outside team games)
+func (g *Game) Teammates(uid string) []string {
+	return g.teamOf(uid)[1:]
+}
+
+// changeLife adds delta (negative for damage) to a player's life
+// Players sharing a life total all move together.
+func (g *Game) changeLife(uid string, delta int) int {
+	life := g.Players[uid].Life + delta
+	if !g.SharedLife {
+		g.Players[uid].Life = life
+		return life
+	}
+	for _, mate := range g.teamOf(uid) {
+		g.Players[mate].Life = life
+	}
+	return life
+}
+
+// winners returns the players who share a win: the winner and their teammates
+func (g *Game) winners(winner string) []string {
+	return g.teamOf(winner)
+}
@@ -0,0 +1,225 @@
+// validate.go - Consistency checks for card and deck data (used by cardlint and at startup)
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KnownAbilities lists the keyword abilities the rules engine understands
+var KnownAbilities = map[string]bool{
+	"Taunt":        true,
+	"Haste":        true,
+	"Flying":       true,
+	"Trample":      true,
+	"Vigilance":    true,
+	"Reach":        true,
+	"FirstStrike":  true,
+	"DoubleStrike": true,
+}
+
+// ValidAttackTargetValues lists the accepted ValidAttackTargets settings ("" means Any)
+var ValidAttackTargetValues = map[string]bool{
+	"":          true,
+	"Any":       true,
+	"Player":    true,
+	"Creatures": true,
+}
+
+// DataProblem is one issue found in card or deck data
+type DataProblem struct {
+	File    string // File the problem was found in
+	Kind    string // "card" or "deck"
+	ID      int
+	Name    string
+	Message string
+}
+
+func (p DataProblem) Error() string {
+	return fmt.Sprintf("%s: %s %d (%s): %s", p.File, p.Kind, p.ID, p.Name, p.Message)
+}
+
+// ValidateData checks cards and decks against each other and returns every problem found
+func ValidateData(cardsPath string, cards []Card, decksPath string, decks []Deck) []DataProblem {
+	problems := []DataProblem{}
+	cardsFile := filepath.Base(cardsPath)
+	decksFile := filepath.Base(decksPath)
+
+	byID := map[int]Card{}
+	for _, card := range cards {
+		cardProblem := func(format string, args ...interface{}) {
+			problems = append(problems, DataProblem{
+				File: cardsFile, Kind: "card", ID: card.ID, Name: card.Name,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		if _, dup := byID[card.ID]; dup {
+			cardProblem("duplicate card ID")
+		}
+		byID[card.ID] = card
+
+		for _, ability := range card.Abilities {
+			if !KnownAbilities[ability] {
+				cardProblem("unknown ability %q", ability)
+			}
+		}
+		if !ValidAttackTargetValues[card.ValidAttackTargets] {
+			cardProblem("invalid ValidAttackTargets %q", card.ValidAttackTargets)
+		}
+		if card.Rarity != "" && !KnownRarities[card.Rarity] {
+			cardProblem("unknown Rarity %q", card.Rarity)
+		}
+
+		for _, err := range ValidateScript(card.CustomScript) {
+			cardProblem("CustomScript: %v", err)
+		}
+		for i, ability := range card.ActivatedAbilities {
+			for _, err := range ValidateScript(ability.Script) {
+				cardProblem("ActivatedAbilities[%d] script: %v", i, err)
+			}
+		}
+		if card.HeroPower != nil {
+			for _, err := range ValidateScript(card.HeroPower.Script) {
+				cardProblem("HeroPower script: %v", err)
+			}
+		}
+
+		costs := []ManaCost{card.Cost}
+		for _, alt := range card.AltCosts {
+			costs = append(costs, alt.Cost)
+		}
+		for _, cost := range costs {
+			for _, symbol := range cost.Hybrid {
+				if _, err := parseHybrid(symbol); err != nil {
+					cardProblem("%v", err)
+				}
+			}
+		}
+
+		if card.CardType == "Land" && len(card.ManaOptions()) == 0 {
+			cardProblem("land produces no mana (set Provides or ManaChoices)")
+		}
+		if card.CardType != "Land" && (len(card.ManaChoices) > 0 || card.EntersTapped) {
+			cardProblem("only lands can have ManaChoices or EntersTapped")
+		}
+	}
+
+	seenDecks := map[int]bool{}
+	for _, deck := range decks {
+		deckProblem := func(format string, args ...interface{}) {
+			problems = append(problems, DataProblem{
+				File: decksFile, Kind: "deck", ID: deck.ID, Name: deck.Name,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		if seenDecks[deck.ID] {
+			deckProblem("duplicate deck ID")
+		}
+		seenDecks[deck.ID] = true
+
+		for _, msg := range DefaultRules.deckProblems(deck, byID) {
+			deckProblem("%s", msg)
+		}
+	}
+
+	return problems
+}
+
+// ValidateDeck checks a deck against the standard deck-building rules using the given pool's cards
+// Returns nil if the deck is legal, otherwise an error listing every problem
+func ValidateDeck(deck Deck, pool *CardPool) error {
+	return DefaultRules.ValidateDeck(deck, pool)
+}
+
+// ValidateDeck checks a deck against this rule set's deck-building rules
+func (r *RuleSet) ValidateDeck(deck Deck, pool *CardPool) error {
+	problems := r.deckProblems(deck, pool.Cards)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid deck: %s", strings.Join(problems, "; "))
+}
+
+// deckProblems lists everything wrong with a deck: sizes, leader, card placement and copy limits
+func (r *RuleSet) deckProblems(deck Deck, cards map[int]Card) []string {
+	problems := []string{}
+
+	if len(deck.MainDeck) > r.MaxMainDeckSize {
+		problems = append(problems, fmt.Sprintf("%d main deck cards, max is %d", len(deck.MainDeck), r.MaxMainDeckSize))
+	}
+	if len(deck.Vault) > r.MaxVaultSize {
+		problems = append(problems, fmt.Sprintf("%d vault cards, max is %d", len(deck.Vault), r.MaxVaultSize))
+	}
+	if len(deck.Sideboard) > MaxSideboardSize {
+		problems = append(problems, fmt.Sprintf("%d sideboard cards, max is %d", len(deck.Sideboard), MaxSideboardSize))
+	}
+
+	if leader, ok := cards[deck.Leader]; !ok {
+		problems = append(problems, fmt.Sprintf("leader references missing card %d", deck.Leader))
+	} else if leader.CardType != "Creature" {
+		problems = append(problems, fmt.Sprintf("leader %d (%s) is a %s, not a creature", leader.ID, leader.Name, leader.CardType))
+	}
+
+	copies := map[int]int{}
+	for _, id := range deck.MainDeck {
+		copies[id]++
+	}
+	for _, id := range uniqueIDs(deck.MainDeck) {
+		card, ok := cards[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("MainDeck references missing card %d", id))
+		} else if card.CardType == "Land" {
+			problems = append(problems, fmt.Sprintf("MainDeck contains land %d (%s)", id, card.Name))
+		}
+		if r.MaxCopiesPerCard > 0 && copies[id] > r.MaxCopiesPerCard {
+			problems = append(problems, fmt.Sprintf("MainDeck has %d copies of card %d, max is %d", copies[id], id, r.MaxCopiesPerCard))
+		}
+	}
+	for _, id := range uniqueIDs(deck.Vault) {
+		card, ok := cards[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("Vault references missing card %d", id))
+		} else if card.CardType != "Land" {
+			problems = append(problems, fmt.Sprintf("Vault contains non-land %d (%s)", id, card.Name))
+		}
+	}
+	for _, id := range uniqueIDs(deck.Sideboard) {
+		if _, ok := cards[id]; !ok {
+			problems = append(problems, fmt.Sprintf("Sideboard references missing card %d", id))
+		}
+	}
+
+	return problems
+}
+
+// ValidateFiles reads both data files and validates them together
+// A read or parse failure is returned as the error; data problems are returned as the list
+func ValidateFiles(cardsPath, decksPath string) ([]DataProblem, error) {
+	cards, err := ReadCards(cardsPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", cardsPath, err)
+	}
+	decks, err := ReadDecks(decksPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", decksPath, err)
+	}
+	return ValidateData(cardsPath, cards, decksPath, decks), nil
+}
+
+// uniqueIDs returns the distinct IDs sorted, so a repeated card is only reported once
+func uniqueIDs(ids []int) []int {
+	seen := map[int]bool{}
+	out := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,22 @@
+#!/bin/sh
+# deploy.sh - Build the latest code next to the live server, then ask it to drain and restart
+# The server never builds itself: if the build fails, the running server is left alone.
+#
+# Needs CARDGAME_ADMIN_TOKEN; CARDGAME_URL defaults to http://localhost:8080.
+# The service manager (e.g. systemd with Restart=always) starts ./cardgame again once it exits.
+set -eu
+
+cd "$(dirname "$0")/.."
+: "${CARDGAME_ADMIN_TOKEN:?set CARDGAME_ADMIN_TOKEN}"
+URL="${CARDGAME_URL:-http://localhost:8080}"
+TIMEOUT="${DRAIN_TIMEOUT_SECONDS:-1800}"
+
+git pull origin main
+go vet ./...
+go build -o cardgame.new ./cmd/server
+mv cardgame.new cardgame
+
+curl -fsS -X POST \
+    -H "Authorization: Bearer $CARDGAME_ADMIN_TOKEN" \
+    -d "{\"restart\": true, \"timeoutSeconds\": $TIMEOUT}" \
+    "$URL/admin/drain"
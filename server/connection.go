@@ -3,8 +3,11 @@ package server
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"card-game/game"
 
@@ -12,21 +15,45 @@ import (
 )
 
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin: func(r *http.Request) bool { return GameHub.CheckOrigin(r) },
 }
 
 // Connection represents a WebSocket connection to a client
 type Connection struct {
 	ws        *websocket.Conn
+	writeMu   sync.Mutex // Only one goroutine may write to ws at a time
 	PlayerUID string
-	GameID    string
+	gameID    atomic.Value // string; set by the hub under its lock, read with GameID
+}
+
+// GameID returns the game the connection is in ("" for none)
+// The hub moves connections between games from other goroutines, so it's read atomically.
+func (c *Connection) GameID() string {
+	id, _ := c.gameID.Load().(string)
+	return id
+}
+
+// write sends a text message; hub broadcasts, the matchmaker and the turn timer
+// write from their own goroutines, so writes take turns
+func (c *Connection) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// close sends a close frame with the reason and closes the socket
+func (c *Connection) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.ws.Close()
 }
 
 // ServeWs handles WebSocket upgrade requests
 func ServeWs(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("upgrade error:", err)
+		slog.Warn("upgrade error", "err", err, "origin", r.Header.Get("Origin"))
 		return
 	}
 
@@ -50,27 +77,72 @@ func (c *Connection) readLoop() {
 
 		var action game.Action
 		if err := json.Unmarshal(msgBytes, &action); err != nil {
-			log.Println("bad action:", err)
+			slog.Warn("bad action", "err", err)
 			continue
 		}
+		slog.Debug("action", "type", action.Type, "player", action.PlayerUID, "game", c.GameID())
 
 		switch action.Type {
 		case "get_cards":
 			c.handleGetCards(action)
 		case "get_decks":
 			c.handleGetDecks(action)
+		case "get_rule_sets":
+			c.handleGetRuleSets(action)
 		case "start_game":
 			c.handleStartGame(action)
-		case "join_game":
-			c.handleJoinGame(action)
+		case "join_queue", "join_game":
+			c.handleJoinQueue(action)
+		case "leave_queue":
+			c.handleLeaveQueue(action)
 		case "list_games":
 			c.handleListGames(action)
 		case "join_specific_game":
 			c.handleJoinSpecificGame(action)
+		case "begin_game":
+			c.handleBeginGame(action)
+		case "kick_player":
+			c.handleKickPlayer(action)
 		case "leave_game":
 			c.handleLeaveGame(action)
 		case "reconnect_game":
 			c.handleReconnectGame(action)
+		case "list_my_decks":
+			c.handleListMyDecks(action)
+		case "save_deck", "update_deck":
+			c.handleSaveDeck(action)
+		case "delete_deck":
+			c.handleDeleteDeck(action)
+		case "export_deck":
+			c.handleExportDeck(action)
+		case "import_deck":
+			c.handleImportDeck(action)
+		case "get_profile":
+			c.handleGetProfile(action)
+		case "set_display_name":
+			c.handleSetDisplayName(action)
+		case "get_match_history":
+			c.handleGetMatchHistory(action)
+		case "get_leaderboard":
+			c.handleGetLeaderboard(action)
+		case "get_match_deck":
+			c.handleGetMatchDeck(action)
+		case "sideboard":
+			c.handleSideboard(action)
+		case "choose_first":
+			c.handleChooseFirst(action)
+		case "create_draft":
+			c.handleCreateDraft(action)
+		case "join_draft":
+			c.handleJoinDraft(action)
+		case "start_draft":
+			c.handleStartDraft(action)
+		case "draft_pick":
+			c.handleDraftPick(action)
+		case "build_draft_deck":
+			c.handleBuildDraftDeck(action)
+		case "leave_draft":
+			c.handleLeaveDraft(action)
 		case "chat":
 			c.handleChat(action)
 		default:
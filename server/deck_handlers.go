@@ -0,0 +1,141 @@
+// deck_handlers.go - WebSocket handlers for player-built decks
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"card-game/game"
+)
+
+var errMissingDeck = errors.New("missing deck")
+
+// deckInfo describes a custom deck for the client, including the leader's name
+func deckInfo(deck game.Deck) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         deck.ID,
+		"name":       deck.Name,
+		"leaderId":   deck.Leader,
+		"leaderName": game.CurrentPool().Card(deck.Leader).Name,
+		"mainDeck":   deck.MainDeck,
+		"vault":      deck.Vault,
+		"sideboard":  deck.Sideboard,
+		"custom":     true,
+	}
+}
+
+func (c *Connection) handleListMyDecks(action game.Action) {
+	decks := make([]map[string]interface{}, 0)
+	for _, deck := range game.PlayerDecks.List(action.PlayerUID) {
+		decks = append(decks, deckInfo(deck))
+	}
+
+	events := []game.Event{
+		{
+			Type: "MyDeckList",
+			Data: map[string]interface{}{
+				"decks":            decks,
+				"maxMainDeck":      game.DefaultRules.MaxMainDeckSize,
+				"maxVault":         game.DefaultRules.MaxVaultSize,
+				"maxCopiesPerCard": game.DefaultRules.MaxCopiesPerCard,
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleSaveDeck(action game.Action) {
+	if action.Deck == nil {
+		c.sendError(errMissingDeck)
+		return
+	}
+
+	var deck game.Deck
+	var err error
+	if action.Type == "update_deck" {
+		deck, err = game.PlayerDecks.Update(action.PlayerUID, *action.Deck)
+	} else {
+		deck, err = game.PlayerDecks.Save(action.PlayerUID, *action.Deck)
+	}
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	events := []game.Event{
+		{
+			Type: "DeckSaved",
+			Data: map[string]interface{}{
+				"deck": deckInfo(deck),
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleDeleteDeck(action game.Action) {
+	if err := game.PlayerDecks.Delete(action.PlayerUID, action.DeckID); err != nil {
+		c.sendError(err)
+		return
+	}
+
+	events := []game.Event{
+		{
+			Type: "DeckDeleted",
+			Data: map[string]interface{}{
+				"deckId": action.DeckID,
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleExportDeck(action game.Action) {
+	deck, ok := game.LookupDeck(action.PlayerUID, action.DeckID)
+	if !ok {
+		c.sendError(fmt.Errorf("deck not found: %d", action.DeckID))
+		return
+	}
+
+	events := []game.Event{
+		{
+			Type: "DeckCode",
+			Data: map[string]interface{}{
+				"deckId": deck.ID,
+				"name":   deck.Name,
+				"code":   game.EncodeDeck(deck),
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleImportDeck(action game.Action) {
+	deck, err := game.ImportDeck(action.PlayerUID, action.DeckCode, action.DeckName)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	events := []game.Event{
+		{
+			Type: "DeckSaved",
+			Data: map[string]interface{}{
+				"deck":     deckInfo(deck),
+				"imported": true,
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
@@ -0,0 +1,124 @@
+// draft_handlers.go - WebSocket handlers for booster draft and sealed
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"card-game/game"
+)
+
+var errNotInDraft = errors.New("not in a draft")
+
+// draftEvents describes a draft as one player sees it
+func draftEvents(d *game.Draft, uid string) []game.Event {
+	return []game.Event{
+		{
+			Type: "DraftUpdate",
+			Data: map[string]interface{}{
+				"draft": d.View(uid),
+			},
+		},
+	}
+}
+
+// broadcastDraft sends every player at the table their own view of the draft
+func broadcastDraft(d *game.Draft) {
+	for _, uid := range d.Players() {
+		sendToPlayer(uid, draftEvents(d, uid))
+	}
+}
+
+func (c *Connection) handleCreateDraft(action game.Action) {
+	c.PlayerUID = action.PlayerUID
+	d, err := game.Drafts.CreateDraft(action.PlayerUID, action.Format, action.MaxPlayers)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	resp, _ := json.Marshal(draftEvents(d, action.PlayerUID))
+	c.write(resp)
+}
+
+func (c *Connection) handleJoinDraft(action game.Action) {
+	c.PlayerUID = action.PlayerUID
+	d, err := game.Drafts.JoinDraft(action.DraftID, action.PlayerUID)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	broadcastDraft(d)
+}
+
+func (c *Connection) handleStartDraft(action game.Action) {
+	d, err := game.Drafts.StartDraft(action.DraftID, c.PlayerUID)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	broadcastDraft(d)
+}
+
+func (c *Connection) handleDraftPick(action game.Action) {
+	d := game.Drafts.PlayerDraft(c.PlayerUID)
+	if d == nil {
+		c.sendError(errNotInDraft)
+		return
+	}
+	if err := d.Pick(c.PlayerUID, action.CardID); err != nil {
+		c.sendError(err)
+		return
+	}
+	broadcastDraft(d)
+}
+
+func (c *Connection) handleBuildDraftDeck(action game.Action) {
+	if action.Deck == nil {
+		c.sendError(errMissingDeck)
+		return
+	}
+	d := game.Drafts.PlayerDraft(c.PlayerUID)
+	if d == nil {
+		c.sendError(errNotInDraft)
+		return
+	}
+	deck, err := game.Drafts.BuildDraftDeck(d.ID, c.PlayerUID, *action.Deck)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	// The deck is saved like any custom deck, so it's picked from the deck list to play
+	events := []game.Event{
+		{
+			Type: "DeckSaved",
+			Data: map[string]interface{}{
+				"deck":    deckInfo(deck),
+				"draftId": d.ID,
+			},
+		},
+	}
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+	broadcastDraft(d)
+}
+
+func (c *Connection) handleLeaveDraft(action game.Action) {
+	d, closed := game.Drafts.LeaveDraft(c.PlayerUID)
+	if d == nil {
+		c.sendError(errNotInDraft)
+		return
+	}
+
+	// The host leaving a lobby closes it
+	if closed {
+		for _, uid := range d.Players() {
+			if uid != c.PlayerUID {
+				sendToPlayer(uid, []game.Event{{Type: "DraftClosed", Data: map[string]interface{}{"draftId": d.ID}}})
+			}
+		}
+		return
+	}
+	broadcastDraft(d)
+}
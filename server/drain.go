@@ -0,0 +1,136 @@
+// drain.go - Admin drain mode: stop new games, wait for the running ones, then restart
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"card-game/game"
+)
+
+// DefaultDrainTimeout is how long a drain waits for running games when the request doesn't say
+const DefaultDrainTimeout = 30 * time.Minute
+
+// drainRequest is the optional JSON body of POST /admin/drain
+type drainRequest struct {
+	TimeoutSeconds int  `json:"timeoutSeconds"`
+	Restart        bool `json:"restart"` // Exit once drained so the service manager starts the new build
+	Force          bool `json:"force"`   // Restart at the timeout even if games are still running
+}
+
+// drain handles POST /admin/drain
+func (a *adminAPI) drain(r *http.Request) (int, string) {
+	req := drainRequest{}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, 4096))
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return http.StatusBadRequest, fmt.Sprintf("Bad drain request: %v\n", err)
+		}
+	}
+	timeout := DefaultDrainTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	if a.drainCancel != nil {
+		return http.StatusConflict, "Already waiting for a drain\n"
+	}
+	a.drainCancel = make(chan struct{})
+	game.Manager.SetDraining(true)
+
+	running := game.Manager.RunningGames()
+	slog.Info("draining", "runningGames", running, "timeout", timeout, "restart", req.Restart)
+	broadcastDraining(true, req.Restart)
+	go a.waitForDrain(a.drainCancel, time.Now().Add(timeout), req)
+
+	return http.StatusAccepted, fmt.Sprintf("Draining, %d game(s) running\n", running)
+}
+
+// undrain handles POST /admin/undrain, going back to starting new games
+func (a *adminAPI) undrain(r *http.Request) (int, string) {
+	a.drainMu.Lock()
+	defer a.drainMu.Unlock()
+	if !game.Manager.Draining() {
+		return http.StatusConflict, "Not draining\n"
+	}
+	if a.drainCancel != nil {
+		close(a.drainCancel)
+		a.drainCancel = nil
+	}
+	game.Manager.SetDraining(false)
+
+	slog.Info("drain cancelled")
+	broadcastDraining(false, false)
+	return http.StatusOK, "Drain cancelled\n"
+}
+
+// status handles POST /admin/status
+func (a *adminAPI) status(r *http.Request) (int, string) {
+	status := map[string]interface{}{
+		"draining":     game.Manager.Draining(),
+		"runningGames": game.Manager.RunningGames(),
+		"games":        len(game.Manager.GetAllGameIDs()),
+		"cardPool":     game.CurrentPool().Version,
+	}
+	data, _ := json.MarshalIndent(status, "", "  ")
+	return http.StatusOK, string(data) + "\n"
+}
+
+// waitForDrain checks every second until no games are running or the deadline passes
+// Games still running at the deadline keep the server draining unless the drain was forced;
+// another drain request can then wait longer or force the restart.
+func (a *adminAPI) waitForDrain(cancel chan struct{}, deadline time.Time, req drainRequest) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	defer func() {
+		a.drainMu.Lock()
+		if a.drainCancel == cancel {
+			a.drainCancel = nil
+		}
+		a.drainMu.Unlock()
+	}()
+	for {
+		select {
+		case <-cancel:
+			return
+		case now := <-ticker.C:
+			running := game.Manager.RunningGames()
+			if running > 0 && now.Before(deadline) {
+				continue
+			}
+			if running > 0 && !req.Force {
+				slog.Warn("drain timed out, still draining - undrain or force a restart", "runningGames", running)
+				return
+			}
+			slog.Info("drained", "runningGames", running)
+			if req.Restart {
+				slog.Info("shutting down for the service manager to restart the server")
+				if a.cfg.Restart == nil {
+					os.Exit(0)
+				}
+				a.cfg.Restart()
+			}
+			return
+		}
+	}
+}
+
+// broadcastDraining tells every client whether new games can be started
+func broadcastDraining(draining, restart bool) {
+	GameHub.BroadcastAll([]game.Event{
+		{
+			Type: "ServerDraining",
+			Data: map[string]interface{}{
+				"draining": draining,
+				"restart":  restart,
+			},
+		},
+	})
+}
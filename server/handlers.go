@@ -3,37 +3,50 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 
 	"card-game/game"
-
-	"github.com/gorilla/websocket"
 )
 
+// sendError sends an Error event to this connection only
+func (c *Connection) sendError(err error) {
+	events := []game.Event{
+		{Type: "Error", Data: map[string]interface{}{"message": err.Error()}},
+	}
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
 func (c *Connection) handleGetCards(action game.Action) {
 	// Send all cards to the client for local lookup
-	cards := make(map[int]game.Card)
-	for id, card := range game.CardDB {
-		cards[id] = card
+	// Players in a game get the card data that game started with
+	pool := game.CurrentPool()
+	if c.GameID() != "" {
+		if g := game.Manager.GetGame(c.GameID()); g != nil && g.Pool != nil {
+			pool = g.Pool
+		}
 	}
 
 	events := []game.Event{
 		{
 			Type: "CardList",
 			Data: map[string]interface{}{
-				"cards": cards,
+				"cards":   pool.Cards,
+				"version": pool.Version,
 			},
 		},
 	}
 
 	resp, _ := json.Marshal(events)
-	c.ws.WriteMessage(websocket.TextMessage, resp)
+	c.write(resp)
 }
 
 func (c *Connection) handleGetDecks(action game.Action) {
 	// Build deck list with leader card info
+	pool := game.CurrentPool()
 	decks := make([]map[string]interface{}, 0)
-	for _, deck := range game.DeckDB {
-		leaderCard := game.CardDB[deck.Leader]
+	for _, deck := range pool.Decks {
+		leaderCard := pool.Card(deck.Leader)
 		decks = append(decks, map[string]interface{}{
 			"id":         deck.ID,
 			"name":       deck.Name,
@@ -52,85 +65,73 @@ func (c *Connection) handleGetDecks(action game.Action) {
 	}
 
 	resp, _ := json.Marshal(events)
-	c.ws.WriteMessage(websocket.TextMessage, resp)
+	c.write(resp)
 }
 
-func (c *Connection) handleStartGame(action game.Action) {
-	c.PlayerUID = action.PlayerUID
-
-	g, _, err := game.Manager.CreateGame(action.PlayerUID, action.DeckID)
-	if err != nil {
-		events := []game.Event{
-			{Type: "Error", Data: map[string]interface{}{"message": err.Error()}},
-		}
-		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
-		return
-	}
-
-	c.GameID = g.ID
-	GameHub.JoinGame(c, g.ID)
-
+func (c *Connection) handleGetRuleSets(action game.Action) {
 	events := []game.Event{
 		{
-			Type: "GameCreated",
+			Type: "RuleSetList",
 			Data: map[string]interface{}{
-				"gameId":    g.ID,
-				"playerUid": action.PlayerUID,
-				"message":   "Waiting for opponent...",
+				"ruleSets": game.RuleSetDB,
 			},
 		},
 	}
 
 	resp, _ := json.Marshal(events)
-	c.ws.WriteMessage(websocket.TextMessage, resp)
+	c.write(resp)
 }
 
-func (c *Connection) handleJoinGame(action game.Action) {
+func (c *Connection) handleStartGame(action game.Action) {
 	c.PlayerUID = action.PlayerUID
 
-	g, _, err := game.Manager.JoinGame(action.PlayerUID, action.DeckID)
+	opts := game.GameOptions{
+		Ranked:   action.Ranked,
+		Private:  action.Private,
+		Password: action.Password,
+		BestOf:   action.BestOf,
+
+		MaxPlayers: action.MaxPlayers,
+		Teams:      action.Teams,
+		SharedLife: action.SharedLife,
+
+		RuleSet: action.RuleSet,
+	}
+	g, _, err := game.Manager.CreateGame(action.PlayerUID, action.DeckID, opts)
 	if err != nil {
 		events := []game.Event{
-			{
-				Type: "Error",
-				Data: map[string]interface{}{
-					"message": err.Error(),
-				},
-			},
+			{Type: "Error", Data: map[string]interface{}{"message": err.Error()}},
 		}
 		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
+		c.write(resp)
 		return
 	}
 
-	c.GameID = g.ID
 	GameHub.JoinGame(c, g.ID)
+	ensureProfile(action.PlayerUID)
+	leaveQueueForGame(action.PlayerUID)
 
-	// Build player info including hands
-	playersInfo := make(map[string]interface{})
-	for uid, player := range g.Players {
-		playersInfo[uid] = map[string]interface{}{
-			"hand":        player.Hand,
-			"leader":      player.Leader,
-			"deckSize":    len(player.DrawPile),
-			"vaultSize":   len(player.VaultPile),
-			"discardSize": len(player.Discard),
-		}
-	}
-
-	// Broadcast mulligan phase to both players
 	events := []game.Event{
 		{
-			Type: "MulliganPhase",
+			Type: "GameCreated",
 			Data: map[string]interface{}{
-				"gameId":  g.ID,
-				"players": playersInfo,
+				"gameId":     g.ID,
+				"playerUid":  action.PlayerUID,
+				"message":    "Waiting for opponent...",
+				"inviteCode": g.InviteCode,
+				"locked":     g.IsLocked(),
+				"matchId":    g.MatchID,
+				"bestOf":     max(opts.BestOf, 1),
+				"maxPlayers": g.Seats(),
+				"teams":      g.TeamGame,
+				"sharedLife": g.SharedLife,
+				"ruleSet":    g.Rules.Name,
 			},
 		},
 	}
 
-	GameHub.Broadcast(g.ID, events)
+	resp, _ := json.Marshal(events)
+	c.write(resp)
 }
 
 func (c *Connection) handleListGames(action game.Action) {
@@ -146,63 +147,81 @@ func (c *Connection) handleListGames(action game.Action) {
 	}
 
 	resp, _ := json.Marshal(events)
-	c.ws.WriteMessage(websocket.TextMessage, resp)
+	c.write(resp)
 }
 
 func (c *Connection) handleJoinSpecificGame(action game.Action) {
 	c.PlayerUID = action.PlayerUID
 
-	g, _, err := game.Manager.JoinSpecificGame(action.GameID, action.PlayerUID, action.DeckID)
+	secret := action.InviteCode
+	if secret == "" {
+		secret = action.Password
+	}
+	g, _, err := game.Manager.JoinSpecificGame(action.GameID, action.PlayerUID, action.DeckID, secret)
 	if err != nil {
 		events := []game.Event{
 			{Type: "Error", Data: map[string]interface{}{"message": err.Error()}},
 		}
 		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
+		c.write(resp)
 		return
 	}
 
-	c.GameID = g.ID
 	GameHub.JoinGame(c, g.ID)
+	ensureProfile(action.PlayerUID)
+	leaveQueueForGame(action.PlayerUID)
+
+	// Locked lobbies wait for the host to start (or kick) first, other games for every seat
+	if !g.MulliganPhase {
+		GameHub.Broadcast(g.ID, lobbyEvents(g, "LobbyJoined"))
+		return
+	}
+
+	// Broadcast mulligan phase to both players
+	GameHub.Broadcast(g.ID, mulliganPhaseEvents(g))
+}
 
-	// Build player info
+// mulliganPhaseEvents builds the MulliganPhase event sent when a game fills up
+func mulliganPhaseEvents(g *game.Game) []game.Event {
 	playersInfo := make(map[string]interface{})
 	for uid, player := range g.Players {
 		playersInfo[uid] = map[string]interface{}{
 			"hand":        player.Hand,
 			"leader":      player.Leader,
+			"life":        player.Life,
 			"deckSize":    len(player.DrawPile),
 			"vaultSize":   len(player.VaultPile),
 			"discardSize": len(player.Discard),
 		}
 	}
 
-	// Broadcast mulligan phase to both players
-	events := []game.Event{
+	return []game.Event{
 		{
 			Type: "MulliganPhase",
 			Data: map[string]interface{}{
-				"gameId":  g.ID,
-				"players": playersInfo,
+				"gameId":     g.ID,
+				"players":    playersInfo,
+				"turnOrder":  g.TurnOrder,
+				"teams":      g.Teams,
+				"sharedLife": g.SharedLife,
+				"rules":      g.Rules,
 			},
 		},
 	}
-
-	GameHub.Broadcast(g.ID, events)
 }
 
 func (c *Connection) handleGameAction(action game.Action) {
-	if c.GameID == "" {
+	if c.GameID() == "" {
 		events := []game.Event{
 			{Type: "Error", Data: map[string]interface{}{"message": "Not in a game"}},
 		}
 		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
+		c.write(resp)
 		return
 	}
 
 	action.PlayerUID = c.PlayerUID // Use connection's UID
-	g := game.Manager.GetGame(c.GameID)
+	g := game.Manager.GetGame(c.GameID())
 	if g == nil {
 		return
 	}
@@ -210,31 +229,43 @@ func (c *Connection) handleGameAction(action game.Action) {
 	events := g.HandleAction(action)
 
 	// Broadcast events to all players in the game
-	GameHub.Broadcast(c.GameID, events)
+	GameHub.Broadcast(c.GameID(), events)
 }
 
 func (c *Connection) handleLeaveGame(action game.Action) {
-	if c.GameID == "" {
+	if c.GameID() == "" {
 		return
 	}
 
 	// Only notify if game isn't already over
-	g := game.Manager.GetGame(c.GameID)
-	if g != nil && g.Winner == "" {
+	g := game.Manager.GetGame(c.GameID())
+	if _, _, winner := g.Progress(); g != nil && winner == "" {
+		// Leaving a started game counts as a loss in the player's record;
+		// with more than two players the game carries on without them
+		forfeit := g.Forfeit(c.PlayerUID)
+		started, mulliganPhase, winner := g.Progress()
+		if !started && !mulliganPhase {
+			game.Manager.LeaveLobby(g.ID, c.PlayerUID)
+		}
 		events := []game.Event{
 			{
 				Type: "OpponentLeft",
 				Data: map[string]interface{}{
-					"player": c.PlayerUID,
+					"player":    c.PlayerUID,
+					"continues": started && winner == "",
 				},
 			},
 		}
-		GameHub.BroadcastExcept(c.GameID, c, events)
+		GameHub.BroadcastExcept(c.GameID(), c, append(events, forfeit...))
+	} else if g != nil {
+		// Leaving between the games of a match concedes the match
+		if events := g.ForfeitMatch(c.PlayerUID); len(events) > 0 {
+			GameHub.BroadcastExcept(c.GameID(), c, events)
+		}
 	}
 
 	// Remove from game
 	GameHub.LeaveGame(c)
-	c.GameID = ""
 }
 
 func getPlayerUIDs(g *game.Game) []string {
@@ -246,7 +277,7 @@ func getPlayerUIDs(g *game.Game) []string {
 }
 
 func (c *Connection) handleChat(action game.Action) {
-	if c.GameID == "" {
+	if c.GameID() == "" {
 		return
 	}
 
@@ -265,11 +296,23 @@ func (c *Connection) handleChat(action game.Action) {
 			Data: map[string]interface{}{
 				"player":  c.PlayerUID,
 				"message": msg,
+				"channel": action.Channel,
 			},
 		},
 	}
 
-	GameHub.Broadcast(c.GameID, events)
+	// Team chat goes to the sender's team only
+	if action.Channel == "team" {
+		g := game.Manager.GetGame(c.GameID())
+		if g == nil || g.Teams == nil {
+			c.sendError(errors.New("team chat is only for team games"))
+			return
+		}
+		GameHub.BroadcastTo(c.GameID(), append([]string{c.PlayerUID}, g.Teammates(c.PlayerUID)...), events)
+		return
+	}
+
+	GameHub.Broadcast(c.GameID(), events)
 }
 
 func (c *Connection) handleReconnectGame(action game.Action) {
@@ -279,136 +322,34 @@ func (c *Connection) handleReconnectGame(action game.Action) {
 			{Type: "Error", Data: map[string]interface{}{"message": "Game not found"}},
 		}
 		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
-		return
-	}
-
-	// Check if player is in this game
-	player, exists := g.Players[action.PlayerUID]
-	if !exists {
-		events := []game.Event{
-			{Type: "Error", Data: map[string]interface{}{"message": "You are not in this game"}},
-		}
-		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
+		c.write(resp)
 		return
 	}
 
-	// Check if game is already over
-	if g.Winner != "" {
-		events := []game.Event{
-			{Type: "Error", Data: map[string]interface{}{"message": "Game is already over", "winner": g.Winner}},
-		}
-		resp, _ := json.Marshal(events)
-		c.ws.WriteMessage(websocket.TextMessage, resp)
+	// Check the player is in this game and it isn't over, and snapshot its state
+	resp, ok := g.ReconnectEvents(action.PlayerUID)
+	if !ok {
+		c.write(resp)
 		return
 	}
 
 	// Set connection state
 	c.PlayerUID = action.PlayerUID
-	c.GameID = g.ID
 	GameHub.JoinGame(c, g.ID)
 
 	// Clear disconnect status for cleanup tracking
 	g.MarkPlayerReconnected(action.PlayerUID)
 
-	// Find opponent
-	var opponentUID string
-	var opponent *game.Player
-	for uid, p := range g.Players {
-		if uid != action.PlayerUID {
-			opponentUID = uid
-			opponent = p
-			break
-		}
-	}
-
-	// Build reconnect state
-	// Separate player's field into creatures and lands
-	myCreatures := []*game.FieldCard{}
-	myLands := []*game.FieldCard{}
-	for _, fc := range player.Field {
-		card := game.CardDB[fc.CardID]
-		if card.CardType == "Land" {
-			myLands = append(myLands, fc)
-		} else {
-			myCreatures = append(myCreatures, fc)
-		}
-	}
-
-	// Separate opponent's field into creatures and lands
-	opponentCreatures := []*game.FieldCard{}
-	opponentLands := []*game.FieldCard{}
-	if opponent != nil {
-		for _, fc := range opponent.Field {
-			card := game.CardDB[fc.CardID]
-			if card.CardType == "Land" {
-				opponentLands = append(opponentLands, fc)
-			} else {
-				opponentCreatures = append(opponentCreatures, fc)
-			}
-		}
-	}
-
-	opponentLife := 30
-	opponentLeader := 0
-	if opponent != nil {
-		opponentLife = opponent.Life
-		opponentLeader = opponent.Leader
-	}
+	c.write(resp)
 
-	// Check if player already made mulligan decision
-	mulliganDecided := false
-	if g.MulliganDecisions != nil {
-		mulliganDecided = g.MulliganDecisions[action.PlayerUID]
-	}
-
-	events := []game.Event{
+	// Notify opponent that player reconnected
+	reconnectNotify := []game.Event{
 		{
-			Type: "GameReconnected",
+			Type: "PlayerReconnected",
 			Data: map[string]interface{}{
-				"gameId":            g.ID,
-				"playerUid":         action.PlayerUID,
-				"opponentUid":       opponentUID,
-				"currentTurn":       g.Turn,
-				"started":           g.Started,
-				"drawPhase":         g.DrawPhase,
-				"mulliganPhase":     g.MulliganPhase,
-				"mulliganDecided":   mulliganDecided,
-				"myHand":            player.Hand,
-				"myLife":            player.Life,
-				"myField":           myCreatures,
-				"myLands":           myLands,
-				"myManaPool":        player.ManaPool,
-				"myDeckSize":        len(player.DrawPile),
-				"myVaultSize":       len(player.VaultPile),
-				"myDiscardSize":     len(player.Discard),
-				"myLeader":          player.Leader,
-				"opponentLife":      opponentLife,
-				"opponentField":     opponentCreatures,
-				"opponentLands":     opponentLands,
-				"opponentLeader":    opponentLeader,
-				"combatPhase":     g.CombatPhase,
-				"priorityPlayer":  g.PriorityPlayer,
-				"attackingPlayer": g.AttackingPlayer,
-				"pendingAttacks":  g.PendingAttacks,
+				"player": action.PlayerUID,
 			},
 		},
 	}
-
-	resp, _ := json.Marshal(events)
-	c.ws.WriteMessage(websocket.TextMessage, resp)
-
-	// Notify opponent that player reconnected
-	if opponent != nil {
-		reconnectNotify := []game.Event{
-			{
-				Type: "PlayerReconnected",
-				Data: map[string]interface{}{
-					"player": action.PlayerUID,
-				},
-			},
-		}
-		GameHub.BroadcastExcept(g.ID, c, reconnectNotify)
-	}
+	GameHub.BroadcastExcept(g.ID, c, reconnectNotify)
 }
@@ -3,6 +3,9 @@ package server
 import (
     "card-game/game"
     "encoding/json"
+    "net/http"
+    "slices"
+    "strings"
     "sync"
 
     "github.com/gorilla/websocket"
@@ -13,11 +16,40 @@ type Hub struct {
     connections map[*Connection]bool
     // gameID -> list of connections in that game
     gameConns map[string][]*Connection
+
+    cfg HubConfig
+}
+
+// HubConfig holds the connection settings of a hub
+type HubConfig struct {
+    AllowedOrigins []string // Origins allowed to open a WebSocket ("*" = any)
+}
+
+// GameHub is the hub used by the server (replaced at startup with the configured one)
+var GameHub = NewHub(HubConfig{AllowedOrigins: []string{"*"}})
+
+// NewHub creates a hub with no connections
+func NewHub(cfg HubConfig) *Hub {
+    return &Hub{
+        connections: make(map[*Connection]bool),
+        gameConns:   make(map[string][]*Connection),
+        cfg:         cfg,
+    }
 }
 
-var GameHub = &Hub{
-    connections: make(map[*Connection]bool),
-    gameConns:   make(map[string][]*Connection),
+// CheckOrigin reports whether a WebSocket request comes from an allowed origin
+// Requests without an Origin header don't come from a browser and are let through.
+func (h *Hub) CheckOrigin(r *http.Request) bool {
+    origin := r.Header.Get("Origin")
+    if origin == "" {
+        return true
+    }
+    for _, allowed := range h.cfg.AllowedOrigins {
+        if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+            return true
+        }
+    }
+    return false
 }
 
 func (h *Hub) Register(c *Connection) {
@@ -31,30 +63,68 @@ func (h *Hub) Unregister(c *Connection) {
     defer h.mu.Unlock()
     delete(h.connections, c)
 
+    // A player who disconnects while queued is taken out of the queue
+    gameID := c.GameID()
+    if c.PlayerUID != "" && gameID == "" {
+        game.Matchmaking.Cancel(c.PlayerUID)
+    }
+
     // Remove from game connections and track disconnect
-    if c.GameID != "" {
+    if gameID != "" {
         // Mark player as disconnected for cleanup tracking
-        if g := game.Manager.GetGame(c.GameID); g != nil && c.PlayerUID != "" {
+        if g := game.Manager.GetGame(gameID); g != nil && c.PlayerUID != "" {
             g.MarkPlayerDisconnected(c.PlayerUID)
         }
 
-        conns := h.gameConns[c.GameID]
+        conns := h.gameConns[gameID]
         for i, conn := range conns {
             if conn == c {
-                h.gameConns[c.GameID] = append(conns[:i], conns[i+1:]...)
+                h.gameConns[gameID] = append(conns[:i], conns[i+1:]...)
                 break
             }
         }
     }
 }
 
+// PlayerConnections returns the open connections that belong to a player
+func (h *Hub) PlayerConnections(playerUID string) []*Connection {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    conns := []*Connection{}
+    for c := range h.connections {
+        if c.PlayerUID == playerUID {
+            conns = append(conns, c)
+        }
+    }
+    return conns
+}
+
 func (h *Hub) JoinGame(c *Connection, gameID string) {
     h.mu.Lock()
     defer h.mu.Unlock()
-    c.GameID = gameID
+    h.joinGame(c, gameID)
+}
+
+// joinGame adds a connection to a game (caller holds h.mu)
+func (h *Hub) joinGame(c *Connection, gameID string) {
+    c.gameID.Store(gameID)
     h.gameConns[gameID] = append(h.gameConns[gameID], c)
 }
 
+// JoinPlayer adds a player's connections that aren't in a game yet to a game
+// A connection already in another game (another tab, say) stays where it is.
+func (h *Hub) JoinPlayer(playerUID, gameID string) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    for c := range h.connections {
+        if c.PlayerUID == playerUID && c.GameID() == "" {
+            h.joinGame(c, gameID)
+        }
+    }
+}
+
 // Broadcast sends a message to all players in a game
 func (h *Hub) Broadcast(gameID string, msg interface{}) {
     h.mu.RLock()
@@ -62,7 +132,7 @@ func (h *Hub) Broadcast(gameID string, msg interface{}) {
 
     data, _ := json.Marshal(msg)
     for _, c := range h.gameConns[gameID] {
-        c.ws.WriteMessage(websocket.TextMessage, data)
+        c.write(data)
     }
 }
 
@@ -74,25 +144,93 @@ func (h *Hub) BroadcastExcept(gameID string, exclude *Connection, msg interface{
     data, _ := json.Marshal(msg)
     for _, c := range h.gameConns[gameID] {
         if c != exclude {
-            c.ws.WriteMessage(websocket.TextMessage, data)
+            c.write(data)
+        }
+    }
+}
+
+// BroadcastTo sends a message to some of the players in a game
+func (h *Hub) BroadcastTo(gameID string, playerUIDs []string, msg interface{}) {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    data, _ := json.Marshal(msg)
+    for _, c := range h.gameConns[gameID] {
+        if slices.Contains(playerUIDs, c.PlayerUID) {
+            c.write(data)
         }
     }
 }
 
+// BroadcastAll sends a message to every connected client, in a game or not
+func (h *Hub) BroadcastAll(msg interface{}) {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    data, _ := json.Marshal(msg)
+    for c := range h.connections {
+        c.write(data)
+    }
+}
+
+// CloseAll closes every connection with a close frame giving the reason
+func (h *Hub) CloseAll(reason string) {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    for c := range h.connections {
+        c.close(websocket.CloseGoingAway, reason)
+    }
+}
+
+// MoveGame moves every connection in one game to another (the next game of a match)
+// It runs on the handler of whichever player started the next game, so the other
+// players' connections only see their new game ID through GameID.
+func (h *Hub) MoveGame(fromGameID, toGameID string) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    for _, c := range h.gameConns[fromGameID] {
+        h.joinGame(c, toGameID)
+    }
+    delete(h.gameConns, fromGameID)
+}
+
 // LeaveGame removes a connection from its game
 func (h *Hub) LeaveGame(c *Connection) {
     h.mu.Lock()
     defer h.mu.Unlock()
+    h.leaveGame(c)
+}
 
-    if c.GameID == "" {
+// leaveGame takes a connection out of its game (caller holds h.mu)
+func (h *Hub) leaveGame(c *Connection) {
+    gameID := c.GameID()
+    if gameID == "" {
         return
     }
 
-    conns := h.gameConns[c.GameID]
+    conns := h.gameConns[gameID]
     for i, conn := range conns {
         if conn == c {
-            h.gameConns[c.GameID] = append(conns[:i], conns[i+1:]...)
+            h.gameConns[gameID] = append(conns[:i], conns[i+1:]...)
             break
         }
     }
+    c.gameID.Store("")
+}
+
+// RemoveFromGame takes every connection of a player out of a game, returning them
+func (h *Hub) RemoveFromGame(gameID, playerUID string) []*Connection {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    removed := []*Connection{}
+    for _, c := range slices.Clone(h.gameConns[gameID]) {
+        if c.PlayerUID == playerUID {
+            h.leaveGame(c)
+            removed = append(removed, c)
+        }
+    }
+    return removed
 }
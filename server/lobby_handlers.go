@@ -0,0 +1,51 @@
+// lobby_handlers.go - WebSocket handlers for the host of a private lobby
+package server
+
+import (
+	"encoding/json"
+
+	"card-game/game"
+)
+
+// lobbyEvents describes who is in a lobby that's waiting for its host
+func lobbyEvents(g *game.Game, eventType string) []game.Event {
+	return []game.Event{
+		{
+			Type: eventType,
+			Data: map[string]interface{}{
+				"gameId":     g.ID,
+				"host":       g.Host,
+				"players":    g.TurnOrder,
+				"maxPlayers": g.Seats(),
+				"teams":      g.TeamGame,
+			},
+		},
+	}
+}
+
+func (c *Connection) handleBeginGame(action game.Action) {
+	g, err := game.Manager.StartLobbyGame(c.GameID(), c.PlayerUID)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	GameHub.Broadcast(g.ID, mulliganPhaseEvents(g))
+}
+
+func (c *Connection) handleKickPlayer(action game.Action) {
+	g, err := game.Manager.KickPlayer(c.GameID(), c.PlayerUID, action.TargetPlayer)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	// Take the kicked player's connections out of the game before telling them
+	kicked := []game.Event{{Type: "Kicked", Data: map[string]interface{}{"gameId": g.ID}}}
+	resp, _ := json.Marshal(kicked)
+	for _, kc := range GameHub.RemoveFromGame(g.ID, action.TargetPlayer) {
+		kc.write(resp)
+	}
+
+	GameHub.Broadcast(g.ID, lobbyEvents(g, "PlayerKicked"))
+}
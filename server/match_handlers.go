@@ -0,0 +1,93 @@
+// match_handlers.go - WebSocket handlers for sideboarding between the games of a best-of-N match
+package server
+
+import (
+	"encoding/json"
+	"errors"
+
+	"card-game/game"
+)
+
+var errNotInMatch = errors.New("not in a match")
+
+// currentMatch returns the match the connection's game belongs to
+func (c *Connection) currentMatch() (*game.Game, *game.Match, error) {
+	g := game.Manager.GetGame(c.GameID())
+	if g == nil || g.MatchID == "" {
+		return nil, nil, errNotInMatch
+	}
+	m := game.Manager.GetMatch(g.MatchID)
+	if m == nil {
+		return nil, nil, errNotInMatch
+	}
+	return g, m, nil
+}
+
+// matchProgress tells the players about a sideboard or first-player choice,
+// or moves them into the next game once it has started
+func matchProgress(prev *game.Game, m *game.Match, next *game.Game) {
+	if next == nil {
+		GameHub.Broadcast(prev.ID, []game.Event{m.Score("MatchUpdate")})
+		return
+	}
+	GameHub.MoveGame(prev.ID, next.ID)
+	events := []game.Event{m.Score("MatchGameStarting")}
+	GameHub.Broadcast(next.ID, append(events, mulliganPhaseEvents(next)...))
+}
+
+func (c *Connection) handleGetMatchDeck(action game.Action) {
+	_, m, err := c.currentMatch()
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	deck, ok := m.MatchDeck(c.PlayerUID)
+	if !ok {
+		c.sendError(errNotInMatch)
+		return
+	}
+
+	info := deckInfo(deck)
+	info["custom"] = deck.Owner != ""
+	events := []game.Event{
+		{
+			Type: "MatchDeck",
+			Data: map[string]interface{}{
+				"matchId":      m.ID,
+				"deck":         info,
+				"maxSideboard": game.MaxSideboardSize,
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleSideboard(action game.Action) {
+	g, m, err := c.currentMatch()
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	next, err := game.Manager.SubmitSideboard(m.ID, c.PlayerUID, action.Deck)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	matchProgress(g, m, next)
+}
+
+func (c *Connection) handleChooseFirst(action game.Action) {
+	g, m, err := c.currentMatch()
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	next, err := game.Manager.ChooseFirst(m.ID, c.PlayerUID, action.TargetPlayer)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	matchProgress(g, m, next)
+}
@@ -0,0 +1,107 @@
+// matchmaking.go - Queue handlers and matchmaker notifications
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"card-game/game"
+)
+
+var errNotQueued = errors.New("not in the queue")
+
+// StartMatchmaking hooks the matchmaker up to player connections and starts pairing until ctx is done
+func StartMatchmaking(ctx context.Context) {
+	game.Matchmaking.OnMatch = onMatchFound
+	game.Matchmaking.OnStatus = func(playerUID string, status game.QueueStatus) {
+		sendToPlayer(playerUID, []game.Event{{Type: "QueueStatus", Data: queueStatusData(status)}})
+	}
+	game.Matchmaking.OnFailed = func(playerUID string, err error) {
+		sendToPlayer(playerUID, []game.Event{{
+			Type: "QueueLeft",
+			Data: map[string]interface{}{"reason": err.Error()},
+		}})
+	}
+	game.Matchmaking.StartMatchmaking(ctx)
+}
+
+// leaveQueueForGame takes a player who created or joined a game out of the queue
+func leaveQueueForGame(playerUID string) {
+	if game.Matchmaking.Cancel(playerUID) {
+		sendToPlayer(playerUID, []game.Event{{
+			Type: "QueueLeft",
+			Data: map[string]interface{}{"reason": "joined a game"},
+		}})
+	}
+}
+
+// sendToPlayer sends events to every connection of a player
+func sendToPlayer(playerUID string, events []game.Event) {
+	resp, _ := json.Marshal(events)
+	for _, c := range GameHub.PlayerConnections(playerUID) {
+		c.write(resp)
+	}
+}
+
+func queueStatusData(status game.QueueStatus) map[string]interface{} {
+	return map[string]interface{}{
+		"format":      status.Format,
+		"position":    status.Position,
+		"queueSize":   status.QueueSize,
+		"waitSeconds": status.WaitSeconds,
+		"searchRange": status.SearchRange,
+		"rating":      status.Rating,
+	}
+}
+
+// onMatchFound moves both players' connections into the new game and starts the mulligan phase
+func onMatchFound(g *game.Game, format string) {
+	for uid := range g.Players {
+		GameHub.JoinPlayer(uid, g.ID)
+		ensureProfile(uid)
+	}
+
+	events := []game.Event{
+		{
+			Type: "MatchFound",
+			Data: map[string]interface{}{
+				"gameId":  g.ID,
+				"format":  format,
+				"ranked":  g.Ranked,
+				"players": getPlayerUIDs(g),
+			},
+		},
+	}
+	events = append(events, mulliganPhaseEvents(g)...)
+	GameHub.Broadcast(g.ID, events)
+}
+
+func (c *Connection) handleJoinQueue(action game.Action) {
+	c.PlayerUID = action.PlayerUID
+
+	format := action.Format
+	if format == "" && action.Ranked {
+		format = "ranked"
+	}
+	status, err := game.Matchmaking.Enqueue(action.PlayerUID, action.DeckID, format)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+
+	events := []game.Event{{Type: "QueueJoined", Data: queueStatusData(status)}}
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleLeaveQueue(action game.Action) {
+	if !game.Matchmaking.Cancel(c.PlayerUID) {
+		c.sendError(errNotQueued)
+		return
+	}
+
+	events := []game.Event{{Type: "QueueLeft", Data: map[string]interface{}{"reason": "cancelled"}}}
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
@@ -0,0 +1,101 @@
+// profile_handlers.go - WebSocket handlers for player profiles and match history
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"card-game/game"
+)
+
+// ensureProfile creates a profile the first time a player starts or joins a game
+func ensureProfile(uid string) {
+	if err := game.Profiles.Ensure(uid); err != nil {
+		slog.Error("failed to create profile", "player", uid, "err", err)
+	}
+}
+
+// profileUID is the player a profile query is about: TargetPlayer if given, otherwise the sender
+func profileUID(action game.Action) string {
+	if action.TargetPlayer != "" {
+		return action.TargetPlayer
+	}
+	return action.PlayerUID
+}
+
+func (c *Connection) handleGetProfile(action game.Action) {
+	uid := profileUID(action)
+	profile, ok := game.Profiles.Get(uid)
+	if !ok {
+		c.sendError(fmt.Errorf("no profile for %s (play a game first)", uid))
+		return
+	}
+
+	season := game.CurrentSeason()
+	rating, _ := game.Profiles.Rating(season, uid)
+
+	events := []game.Event{
+		{
+			Type: "Profile",
+			Data: map[string]interface{}{
+				"profile": profile,
+				"season":  season,
+				"rating":  rating,
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleSetDisplayName(action game.Action) {
+	if action.PlayerUID == "" {
+		c.sendError(fmt.Errorf("missing player UID"))
+		return
+	}
+	if err := game.Profiles.SetDisplayName(action.PlayerUID, action.Message); err != nil {
+		c.sendError(err)
+		return
+	}
+	c.handleGetProfile(game.Action{PlayerUID: action.PlayerUID})
+}
+
+func (c *Connection) handleGetMatchHistory(action game.Action) {
+	uid := profileUID(action)
+
+	events := []game.Event{
+		{
+			Type: "MatchHistory",
+			Data: map[string]interface{}{
+				"player":  uid,
+				"matches": game.Profiles.History(uid, game.MatchHistoryLimit),
+				"results": game.Profiles.MatchResults(uid, game.MatchHistoryLimit), // Best-of-N matches
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
+
+func (c *Connection) handleGetLeaderboard(action game.Action) {
+	season := action.Season
+	if season == "" {
+		season = game.CurrentSeason()
+	}
+
+	events := []game.Event{
+		{
+			Type: "Leaderboard",
+			Data: map[string]interface{}{
+				"season":  season,
+				"players": game.Profiles.Leaderboard(season, game.LeaderboardSize),
+			},
+		},
+	}
+
+	resp, _ := json.Marshal(events)
+	c.write(resp)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"card-game/game"
+)
+
+// reload handles POST /admin/reload
+// It validates the card and deck files and swaps them in without a restart.
+// Running games keep the card data they started with; new games use the new data.
+func (a *adminAPI) reload(r *http.Request) (int, string) {
+	slog.Info("reload requested, validating card and deck data")
+
+	pool, problems, err := game.ReloadData(a.cfg.CardsFile, a.cfg.DecksFile)
+	if err != nil {
+		msg := fmt.Sprintf("Reload failed: %v\n", err)
+		for _, p := range problems {
+			msg += p.Error() + "\n"
+		}
+		slog.Error("reload failed", "err", err, "problems", len(problems))
+		return http.StatusUnprocessableEntity, msg
+	}
+
+	slog.Info("reloaded card pool", "version", pool.Version, "cards", len(pool.Cards), "decks", len(pool.Decks))
+
+	// Tell clients to refresh their card cache via get_cards
+	GameHub.BroadcastAll([]game.Event{
+		{
+			Type: "CardListUpdated",
+			Data: map[string]interface{}{
+				"version": pool.Version,
+			},
+		},
+	})
+
+	return http.StatusOK, fmt.Sprintf("Reloaded card pool version %d\n", pool.Version)
+}
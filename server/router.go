@@ -4,9 +4,18 @@ import (
     "net/http"
 )
 
-func NewRouter() *http.ServeMux {
+func NewRouter(adminCfg AdminConfig, clientCfg ClientConfig) (*http.ServeMux, error) {
+    client, err := newClientHandler(clientCfg)
+    if err != nil {
+        return nil, err
+    }
+    admin := newAdminAPI(adminCfg)
     mux := http.NewServeMux()
+    mux.Handle("/", client)
     mux.HandleFunc("/ws", ServeWs)
-    mux.HandleFunc("/rebuild", HandleRebuild)
-    return mux
+    mux.HandleFunc("/admin/reload", admin.handler("reload", admin.reload))
+    mux.HandleFunc("/admin/drain", admin.handler("drain", admin.drain))
+    mux.HandleFunc("/admin/undrain", admin.handler("undrain", admin.undrain))
+    mux.HandleFunc("/admin/status", admin.handler("status", admin.status))
+    return mux, nil
 }
@@ -0,0 +1,64 @@
+// shutdown.go - Graceful shutdown: stop taking connections, warn clients, let games finish, close sockets
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"card-game/game"
+)
+
+// ReconnectHint is how long clients are told to wait before reconnecting after a shutdown
+const ReconnectHint = 5 * time.Second
+
+// Shutdown stops the server before ctx's deadline
+// No new connections or games are accepted; running games get until the deadline to finish,
+// and games still running then are lost since games aren't saved to disk.
+func Shutdown(ctx context.Context, srv *http.Server) {
+	game.Manager.SetDraining(true)
+	running := game.Manager.RunningGames()
+	slog.Info("shutting down", "runningGames", running)
+
+	deadline, _ := ctx.Deadline()
+	GameHub.BroadcastAll([]game.Event{
+		{
+			Type: "ServerShuttingDown",
+			Data: map[string]interface{}{
+				"runningGames":          running,
+				"deadline":              deadline,
+				"reconnectAfterSeconds": int(ReconnectHint / time.Second),
+			},
+		},
+	})
+
+	// WebSockets are hijacked connections, so this only stops the listener and idle HTTP requests
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("HTTP shutdown", "err", err)
+	}
+
+	if left := waitForGames(ctx); left > 0 {
+		slog.Warn("shutdown deadline reached with games still running", "runningGames", left)
+	}
+
+	GameHub.CloseAll("server shutting down")
+	slog.Info("server stopped")
+}
+
+// waitForGames waits until no games are running or ctx is done, returning how many are left
+func waitForGames(ctx context.Context) int {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		running := game.Manager.RunningGames()
+		if running == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return running
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// static.go - Serves the web client: embedded with content-hashed asset names, or from disk in dev mode
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"card-game/client"
+)
+
+// ClientConfig says where the web client is served from
+type ClientConfig struct {
+	Dev bool   // Serve from Dir on every request, uncached, for live editing
+	Dir string // The client directory on disk, used in dev mode
+}
+
+const (
+	clientIndex      = "index.html"
+	immutableCaching = "public, max-age=31536000, immutable"
+)
+
+// clientAsset is one file ready to serve
+type clientAsset struct {
+	body  []byte
+	etag  string
+	cache string
+}
+
+// clientHandler serves the client's files
+// Assets are also served under a name with their content hash (client.<hash>.js), which index.html
+// links to, so browsers can keep them forever and still pick up a new build straight away.
+type clientHandler struct {
+	cfg    ClientConfig
+	assets map[string]clientAsset // By URL path without the leading slash; nil in dev mode
+}
+
+func newClientHandler(cfg ClientConfig) (*clientHandler, error) {
+	h := &clientHandler{cfg: cfg}
+	if cfg.Dev {
+		return h, nil
+	}
+	assets, err := loadClientAssets(client.Files)
+	if err != nil {
+		return nil, err
+	}
+	h.assets = assets
+	return h, nil
+}
+
+func (h *clientHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	assets := h.assets
+	if h.cfg.Dev {
+		var err error
+		if assets, err = loadClientAssets(os.DirFS(h.cfg.Dir)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if name == "" {
+		name = clientIndex
+	}
+	asset, ok := assets[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("ETag", asset.etag)
+	w.Header().Set("Cache-Control", asset.cache)
+	if h.cfg.Dev {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(asset.body))
+}
+
+// loadClientAssets reads the client's files and links index.html to the hashed asset names
+func loadClientAssets(files fs.FS) (map[string]clientAsset, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	assets := map[string]clientAsset{}
+	hashed := map[string]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == clientIndex || !servedExtension(name) {
+			continue
+		}
+		body, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, err
+		}
+		hash := contentHash(body)
+		ext := path.Ext(name)
+		hashedName := strings.TrimSuffix(name, ext) + "." + hash[:12] + ext
+		hashed[name] = hashedName
+
+		// The plain name stays reachable for anything that links to it directly
+		assets[name] = clientAsset{body: body, etag: `"` + hash + `"`, cache: "no-cache"}
+		assets[hashedName] = clientAsset{body: body, etag: `"` + hash + `"`, cache: immutableCaching}
+	}
+
+	index, err := fs.ReadFile(files, clientIndex)
+	if err != nil {
+		return nil, err
+	}
+	for name, hashedName := range hashed {
+		index = bytes.ReplaceAll(index, []byte(`"`+name+`"`), []byte(`"`+hashedName+`"`))
+	}
+	assets[clientIndex] = clientAsset{body: index, etag: `"` + contentHash(index) + `"`, cache: "no-cache"}
+	return assets, nil
+}
+
+// servedExtension keeps source files like embed.go out of what's served in dev mode
+func servedExtension(name string) bool {
+	switch path.Ext(name) {
+	case ".js", ".css", ".html", ".png", ".svg", ".ico":
+		return true
+	}
+	return false
+}
+
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,44 @@
+// turn_timer.go - Ends turns that run past their rule set's turn timer
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"card-game/game"
+)
+
+// TurnTimerInterval is how often running games are checked for turns that ran out of time
+const TurnTimerInterval = time.Second
+
+// StartTurnTimer starts the background goroutine that ends timed-out turns, which runs until ctx is done
+func StartTurnTimer(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(TurnTimerInterval)
+		defer ticker.Stop()
+		slog.Info("turn timer routine started")
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("turn timer routine stopped")
+				return
+			case now := <-ticker.C:
+				checkTurnTimers(now)
+			}
+		}
+	}()
+}
+
+// checkTurnTimers ends every turn that's over its time and tells the players
+func checkTurnTimers(now time.Time) {
+	for _, gameID := range game.Manager.GetAllGameIDs() {
+		g := game.Manager.GetGame(gameID)
+		if g == nil {
+			continue
+		}
+		if events := g.TimeOutTurn(now); len(events) > 0 {
+			GameHub.Broadcast(gameID, events)
+		}
+	}
+}